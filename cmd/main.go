@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/ledaian41/go-cms-service/config"
 	"github.com/ledaian41/go-cms-service/pkg/db"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
 	"github.com/ledaian41/go-cms-service/routes"
 	"log"
 	"os"
@@ -27,6 +28,7 @@ import (
 // @name Authorization
 func main() {
 	config.LoadConfig()
+	value_type.SetRichTextAllowedTags(config.Env.RichTextAllowedTags)
 	redisClient := config.InitRedisClient()
 	db := db.Init(config.Env.DbHost, config.Env.DbUser, config.Env.DbPwd)
 	r := routes.InitRoutes(db, redisClient)
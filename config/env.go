@@ -5,10 +5,14 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 )
 
 var Env *AppConfig
 
+// DefaultMaxReferenceDepth is how many levels referenceView expands when MAX_REFERENCE_DEPTH is not set.
+const DefaultMaxReferenceDepth = 3
+
 type AppConfig struct {
 	CachePath              string
 	DbHost                 string
@@ -18,6 +22,10 @@ type AppConfig struct {
 	MaxUploadFileSize      int64
 	MaxTotalUploadFileSize int64
 	AppHost                string
+	AdminToken             string
+	MaxReferenceDepth      int
+	// RichTextAllowedTags overrides the HTML tags RICH_TEXT values keep, empty for the default allowlist.
+	RichTextAllowedTags []string
 }
 
 func LoadConfig() {
@@ -30,6 +38,18 @@ func LoadConfig() {
 	maxUploadFileSize, err := strconv.ParseInt(os.Getenv("MAX_UPLOAD_FILE_SIZE"), 10, 64)
 	maxTotalUploadFileSize, err := strconv.ParseInt(os.Getenv("MAX_TOTAL_UPLOAD_FILE_SIZE"), 10, 64)
 
+	maxReferenceDepth, err := strconv.Atoi(os.Getenv("MAX_REFERENCE_DEPTH"))
+	if err != nil || maxReferenceDepth < 1 {
+		maxReferenceDepth = DefaultMaxReferenceDepth
+	}
+
+	var richTextAllowedTags []string
+	for _, tag := range strings.Split(os.Getenv("RICH_TEXT_ALLOWED_TAGS"), ",") {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); len(tag) > 0 {
+			richTextAllowedTags = append(richTextAllowedTags, tag)
+		}
+	}
+
 	Env = &AppConfig{
 		DbHost:                 os.Getenv("DATABASE_HOST"),
 		DbUser:                 os.Getenv("DATABASE_USER"),
@@ -39,5 +59,8 @@ func LoadConfig() {
 		MaxUploadFileSize:      maxUploadFileSize,
 		MaxTotalUploadFileSize: maxTotalUploadFileSize,
 		AppHost:                os.Getenv("APP_HOST"),
+		AdminToken:             os.Getenv("ADMIN_TOKEN"),
+		MaxReferenceDepth:      maxReferenceDepth,
+		RichTextAllowedTags:    richTextAllowedTags,
 	}
 }
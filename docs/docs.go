@@ -24,9 +24,738 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/aggregate/{typeId}": {
+            "get": {
+                "description": "Count the nodes matching the filters of the list endpoint per group of ` + "`" + `groupBy` + "`" + `, with the ` + "`" + `sum` + "`" + `, ` + "`" + `avg` + "`" + `,\n` + "`" + `min` + "`" + ` and ` + "`" + `max` + "`" + ` of numeric properties, e.g. ` + "`" + `GET /aggregate/product?groupBy=category\u0026sum=price\u0026avg=price` + "`" + `.\nA ` + "`" + `REFERENCE` + "`" + ` group is returned as ` + "`" + `{ id, label }` + "`" + ` where the label is its ` + "`" + `referenceValue` + "`" + ` property.\nWithout ` + "`" + `groupBy` + "`" + ` a single item covers all matching nodes. Groups are ordered by count, largest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "NodeType"
+                ],
+                "summary": "Aggregate nodes by type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type ID",
+                        "name": "typeId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields to group by, ` + "`" + `\u003creference\u003e.\u003cfield\u003e` + "`" + ` groups by a field of a referenced node",
+                        "name": "groupBy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated numeric fields to sum",
+                        "name": "sum",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated numeric fields to average",
+                        "name": "avg",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated numeric fields to take the minimum of",
+                        "name": "min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated numeric fields to take the maximum of",
+                        "name": "max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, see the list endpoint",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Full-text search, see the list endpoint",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{ items: [{ group: {...}, count: n, sum: {...}, avg: {...} }] }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/schema/nodeType": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a node type and its table from a JSON definition, the same shape as a schema file.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schema"
+                ],
+                "summary": "Create node type",
+                "parameters": [
+                    {
+                        "description": "Node type definition",
+                        "name": "definition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/shared_dto.NodeTypeDTO"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apply changes that lose data",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return the migration plan",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "{ message, plan }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "node type exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/schema/nodeType/{tid}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the whole definition of a node type, properties missing from the body are removed.\nThe node type is created when it does not exist. Declare ` + "`" + `renamedFrom` + "`" + ` on a property to rename it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schema"
+                ],
+                "summary": "Replace node type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type ID",
+                        "name": "tid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Node type definition",
+                        "name": "definition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/shared_dto.NodeTypeDTO"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apply changes that lose data",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return the migration plan",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{ message, plan }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "{ error, plan } when data would be lost",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Drop the table of a node type and delete its definition, refused while the table has rows unless force=true\nand while other node types reference it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schema"
+                ],
+                "summary": "Delete node type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type ID",
+                        "name": "tid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Drop the table even if it has rows",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return the migration plan",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{ message, plan }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "{ error, plan } when the table has rows, { error } when the node type is referenced",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add or replace the given property types by pid, the other properties are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schema"
+                ],
+                "summary": "Patch node type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type ID",
+                        "name": "tid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Property types to add or replace",
+                        "name": "definition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/shared_dto.NodeTypeDTO"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apply changes that lose data",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return the migration plan",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{ message, plan }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "{ error, plan } when data would be lost",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/schema/nodeType/{tid}/propertyType": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a property type to a node type and its column to the table.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schema"
+                ],
+                "summary": "Add property type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type ID",
+                        "name": "tid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Property type definition",
+                        "name": "definition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/shared_dto.PropertyTypeDTO"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apply changes that lose data",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return the migration plan",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "{ message, plan }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "{ error } when the property type exists, { error, plan } when data would be lost",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/schema/nodeType/{tid}/propertyType/{pid}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the definition of a property type. A different pid in the body renames the property and its column.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schema"
+                ],
+                "summary": "Replace property type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type ID",
+                        "name": "tid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Property ID",
+                        "name": "pid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Property type definition",
+                        "name": "definition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/shared_dto.PropertyTypeDTO"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apply changes that lose data",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return the migration plan",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{ message, plan }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "{ error, plan } when data would be lost",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a property type and drop its column, refused while the column holds values unless force=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schema"
+                ],
+                "summary": "Delete property type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type ID",
+                        "name": "tid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Property ID",
+                        "name": "pid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Drop the column even if it holds values",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return the migration plan",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{ message, plan }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "{ error, plan } when the column holds values",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change only the given attributes of a property type, e.g. ` + "`" + `{\"required\": true}` + "`" + `.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schema"
+                ],
+                "summary": "Patch property type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type ID",
+                        "name": "tid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Property ID",
+                        "name": "pid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Attributes to change",
+                        "name": "definition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apply changes that lose data",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return the migration plan",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{ message, plan }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "{ error, plan } when data would be lost",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
         "/{typeId}": {
             "get": {
-                "description": "Get nodes of a specific type with pagination, sorting, and flexible filter syntax.\n\\n\n**Filtering syntax** (all remaining URL query params are interpreted as filters):\n- Pattern: ` + "`" + `{field}_{operator}={value}` + "`" + `\n- Supported operators: ` + "`" + `equal` + "`" + `, ` + "`" + `include` + "`" + `, ` + "`" + `in` + "`" + `, ` + "`" + `from` + "`" + `, ` + "`" + `to` + "`" + `, ` + "`" + `fromto` + "`" + `\n- Semantics:\n* ` + "`" + `equal` + "`" + `: exact match (e.g. ` + "`" + `status_equal=published` + "`" + `)\n* ` + "`" + `include` + "`" + `: substring/contains (e.g. ` + "`" + `title_include=hello` + "`" + `)\n* ` + "`" + `in` + "`" + `: membership list, comma-separated (e.g. ` + "`" + `type_in=article,page` + "`" + `)\n* ` + "`" + `from` + "`" + `: lower bound (\u003e=), typically for dates/numbers (e.g. ` + "`" + `createdAt_from=2025-01-01T00:00:00Z` + "`" + `)\n* ` + "`" + `to` + "`" + `: upper bound (\u003c=) (e.g. ` + "`" + `createdAt_to=2025-12-31T23:59:59Z` + "`" + `)\n* ` + "`" + `fromto` + "`" + `: range (e.g. ` + "`" + `price_fromto=10,100` + "`" + `)\n- Examples: ` + "`" + `GET /{typeId}?title_include=guide\u0026status_in=draft,published\u0026createdAt_from=2025-01-01T00:00:00Z` + "`" + `\n\\n\n**Sorting syntax**\n- Pattern: ` + "`" + `\u003cfield\u003e \u003casc|desc\u003e` + "`" + `; default direction is ` + "`" + `asc` + "`" + ` if omitted (e.g., ` + "`" + `createdAt` + "`" + ` == ` + "`" + `createdAt asc` + "`" + `)\n- Multiple fields: separate by comma, evaluated left-to-right (e.g., ` + "`" + `name desc,age asc` + "`" + `)\n- URL encoding: encode spaces as ` + "`" + `%20` + "`" + ` or ` + "`" + `+` + "`" + ` (e.g., ` + "`" + `name%20desc,age%20asc` + "`" + `)\n- Examples: ` + "`" + `GET /{typeId}?sort=createdAt%20desc,id` + "`" + `, ` + "`" + `GET /{typeId}?sort=name%20desc,age%20asc` + "`" + `",
+                "description": "Get nodes of a specific type with pagination, sorting, and flexible filter syntax.\n\\n\n**Filtering syntax** (all remaining URL query params are interpreted as filters):\n- Pattern: ` + "`" + `{field}_{operator}={value}` + "`" + `\n- Supported operators: ` + "`" + `equal` + "`" + `, ` + "`" + `notequal` + "`" + `, ` + "`" + `include` + "`" + `, ` + "`" + `in` + "`" + `, ` + "`" + `notin` + "`" + `, ` + "`" + `from` + "`" + `, ` + "`" + `to` + "`" + `, ` + "`" + `fromto` + "`" + `, ` + "`" + `isnull` + "`" + `, ` + "`" + `notnull` + "`" + `,\n` + "`" + `startswith` + "`" + `, ` + "`" + `endswith` + "`" + `, ` + "`" + `like` + "`" + `, ` + "`" + `regex` + "`" + `, ` + "`" + `contains` + "`" + `\n- Semantics:\n* ` + "`" + `equal` + "`" + `: exact match (e.g. ` + "`" + `status_equal=published` + "`" + `)\n* ` + "`" + `notequal` + "`" + `: different value, rows without a value match too (e.g. ` + "`" + `status_notequal=draft` + "`" + `)\n* ` + "`" + `include` + "`" + `: substring/contains, case-insensitive (e.g. ` + "`" + `title_include=hello` + "`" + `)\n* ` + "`" + `in` + "`" + `: membership list, comma-separated (e.g. ` + "`" + `type_in=article,page` + "`" + `)\n* ` + "`" + `notin` + "`" + `: not in the comma-separated list, rows without a value match too\n* ` + "`" + `from` + "`" + `: lower bound (\u003e=), typically for dates/numbers (e.g. ` + "`" + `createdAt_from=2025-01-01T00:00:00Z` + "`" + `)\n* ` + "`" + `to` + "`" + `: upper bound (\u003c=) (e.g. ` + "`" + `createdAt_to=2025-12-31T23:59:59Z` + "`" + `)\n* ` + "`" + `fromto` + "`" + `: range (e.g. ` + "`" + `price_fromto=10,100` + "`" + `)\n* dates also accept a time relative to now in ` + "`" + `s` + "`" + `, ` + "`" + `m` + "`" + `, ` + "`" + `h` + "`" + `, ` + "`" + `d` + "`" + `, ` + "`" + `w` + "`" + `, ` + "`" + `M` + "`" + ` or ` + "`" + `y` + "`" + ` (e.g. ` + "`" + `createdAt_from=now-7d` + "`" + `)\n* ` + "`" + `isnull` + "`" + ` / ` + "`" + `notnull` + "`" + `: property has no value / has a value, the value is ignored (e.g. ` + "`" + `image_isnull=1` + "`" + `)\n* ` + "`" + `startswith` + "`" + ` / ` + "`" + `endswith` + "`" + `: case-insensitive prefix / suffix of text properties (e.g. ` + "`" + `sku_startswith=SHO-` + "`" + `)\n* ` + "`" + `like` + "`" + `: case-sensitive SQL LIKE pattern on text properties, ` + "`" + `%` + "`" + ` and ` + "`" + `_` + "`" + ` are wildcards (e.g. ` + "`" + `title_like=Go%25` + "`" + `)\n* ` + "`" + `regex` + "`" + `: POSIX regular expression on text properties (e.g. ` + "`" + `sku_regex=^[A-Z]{3}-[0-9]+$` + "`" + `)\n* ` + "`" + `contains` + "`" + `: multi-valued property (` + "`" + `REFERENCES` + "`" + `) holds the value (e.g. ` + "`" + `tags_contains=go` + "`" + `)\n* ` + "`" + `hasany` + "`" + `, ` + "`" + `hasall` + "`" + `: multi-valued property holds any / all of the comma-separated values (e.g. ` + "`" + `tags_hasany=go,rust` + "`" + `)\n- Examples: ` + "`" + `GET /{typeId}?title_include=guide\u0026status_in=draft,published\u0026createdAt_from=2025-01-01T00:00:00Z` + "`" + `\n- Fields are properties of the node type (camelCase or snake_case), system columns (` + "`" + `id` + "`" + `, ` + "`" + `createdAt` + "`" + `, ...)\nor ` + "`" + `\u003creference\u003e.\u003cfield\u003e` + "`" + ` (also ` + "`" + `\u003creference\u003e.\u003cnested\u003e.\u003cfield\u003e` + "`" + `) for fields of referenced nodes, which are\njoined automatically. Unknown fields and values that do not\nmatch the value type of the property return 400.\n\\n\n**Filter expressions** (` + "`" + `filter` + "`" + ` param) combine the same terms with ` + "`" + `AND` + "`" + `, ` + "`" + `OR` + "`" + `, ` + "`" + `NOT` + "`" + ` and parentheses:\n- Term: ` + "`" + `{field}_{operator}:{value}` + "`" + `, double-quote values containing spaces or parentheses\n- ` + "`" + `AND` + "`" + ` binds tighter than ` + "`" + `OR` + "`" + `, keywords are case-insensitive\n- Example: ` + "`" + `filter=(status_equal:draft OR author_equal:me) AND NOT title_include:\"hello world\"` + "`" + `\n- The expression is ANDed with the ` + "`" + `{field}_{operator}` + "`" + ` params\n\\n\n**Sorting syntax**\n- Pattern: ` + "`" + `\u003cfield\u003e \u003casc|desc\u003e` + "`" + `; default direction is ` + "`" + `asc` + "`" + ` if omitted (e.g., ` + "`" + `createdAt` + "`" + ` == ` + "`" + `createdAt asc` + "`" + `)\n- Multiple fields: separate by comma, evaluated left-to-right (e.g., ` + "`" + `name desc,age asc` + "`" + `)\n- URL encoding: encode spaces as ` + "`" + `%20` + "`" + ` or ` + "`" + `+` + "`" + ` (e.g., ` + "`" + `name%20desc,age%20asc` + "`" + `)\n- Examples: ` + "`" + `GET /{typeId}?sort=createdAt%20desc,id` + "`" + `, ` + "`" + `GET /{typeId}?sort=name%20desc,age%20asc` + "`" + `\n- Sort fields are resolved like filter fields, any direction other than ` + "`" + `asc` + "`" + `/` + "`" + `desc` + "`" + ` returns 400.\n- Rows without a value come last in both directions and ` + "`" + `id` + "`" + ` is always the final tie-breaker.\n\\n\n**Pagination**: ` + "`" + `page` + "`" + `/` + "`" + `pageSize` + "`" + ` (offset) or ` + "`" + `cursor` + "`" + ` (keyset). Every page with more rows returns\n` + "`" + `hasNext: true` + "`" + ` and a ` + "`" + `nextCursor` + "`" + `; pass it as ` + "`" + `cursor` + "`" + ` with the same ` + "`" + `sort` + "`" + ` to get the following page.",
                 "consumes": [
                     "application/json"
                 ],
@@ -62,6 +791,19 @@ const docTemplate = `{
                         "name": "pageSize",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Keyset pagination: send an empty ` + "`" + `cursor` + "`" + ` for the first page, then the ` + "`" + `nextCursor` + "`" + ` of the previous page. ` + "`" + `page` + "`" + ` is ignored",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Set to false to skip counting ` + "`" + `total` + "`" + ` and ` + "`" + `totalPage` + "`" + `",
+                        "name": "count",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Sort expression: ` + "`" + `\u003cfield\u003e \u003casc|desc\u003e` + "`" + `, multiple fields separated by comma. Example: ` + "`" + `name desc,age asc` + "`" + `. Default direction is ` + "`" + `asc` + "`" + ` if omitted. Use ` + "`" + `%20` + "`" + ` (or ` + "`" + `+` + "`" + `) to encode spaces in URLs: ` + "`" + `name%20desc,age%20asc` + "`" + `",
@@ -70,20 +812,51 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Dynamic filters: ` + "`" + `{field}_{operator}={value}` + "`" + `. Operators: ` + "`" + `equal|include|in|from|to|fromto` + "`" + `. Example: ` + "`" + `name_equal=ABC\u0026age_from=20` + "`" + `",
+                        "description": "Filter expression: ` + "`" + `{field}_{operator}:{value}` + "`" + ` terms combined with ` + "`" + `AND` + "`" + `, ` + "`" + `OR` + "`" + `, ` + "`" + `NOT` + "`" + ` and parentheses. Example: ` + "`" + `(status_equal:draft OR author_equal:me) AND NOT price_from:100` + "`" + `",
                         "name": "filter",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "true or field name to fetch related records",
+                        "description": "Full-text search over the searchable properties, with web search syntax (phrases, or, -excluded). Results are ordered by rank unless ` + "`" + `sort` + "`" + ` is given",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "With ` + "`" + `q` + "`" + `, adds the matching snippets under ` + "`" + `_highlight` + "`" + `",
+                        "name": "highlight",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated reference paths to expand, e.g. category.parent,tags",
                         "name": "referenceView",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields to return, ` + "`" + `\u003creference\u003e.\u003cfield\u003e` + "`" + ` limits the columns of a joined reference and joins it. Example: ` + "`" + `name,price,category.name` + "`" + `",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "html",
+                            "markdown",
+                            "text"
+                        ],
+                        "type": "string",
+                        "default": "html",
+                        "description": "Representation of RICH_TEXT properties: sanitised ` + "`" + `html` + "`" + `, the ` + "`" + `markdown` + "`" + ` source or plain ` + "`" + `text` + "`" + `",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "{ items: [...], pagination: { page, pageSize, total, hasNext, nextCursor? } }",
+                        "description": "{ items: [...], pagination: { page?, pageSize, total?, totalPage?, hasNext, nextCursor? } }",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -98,8 +871,9 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a new node with form data",
+                "description": "Create a new node from a JSON object or multipart form data.\n- ` + "`" + `application/json` + "`" + `: values keep their native types (numbers, booleans, ` + "`" + `null` + "`" + `, arrays).\n- ` + "`" + `multipart/form-data` + "`" + `: one field per property, FILE properties are uploaded as files.\nA ` + "`" + `_json` + "`" + ` part holding a JSON object may be sent alongside, its keys are merged with the form fields.",
                 "consumes": [
+                    "application/json",
                     "multipart/form-data"
                 ],
                 "produces": [
@@ -135,6 +909,12 @@ const docTemplate = `{
                         "description": "Image file",
                         "name": "image",
                         "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON object merged with the form fields",
+                        "name": "_json",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -143,13 +923,32 @@ const docTemplate = `{
                     },
                     "400": {
                         "description": "Bad Request"
+                    },
+                    "409": {
+                        "description": "concurrent writes kept taking the slug",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "unsupported content type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "{ error: \\\"validation failed\\\", fields: [{ field, rule, message }] }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
         "/{typeId}/{id}": {
             "get": {
-                "description": "Get detailed information of a specific node",
+                "description": "Get detailed information of a specific node, designated by its id or, when the type has a ` + "`" + `SLUG` + "`" + `\nproperty, by its slug (e.g. ` + "`" + `GET /product/soft-cotton-tee` + "`" + `). An id takes precedence over a slug.",
                 "consumes": [
                     "application/json"
                 ],
@@ -170,10 +969,40 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Node ID",
+                        "description": "Node ID or slug",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated reference paths to expand, e.g. category.parent,tags",
+                        "name": "referenceView",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields to return, ` + "`" + `\u003creference\u003e.\u003cfield\u003e` + "`" + ` limits the columns of a joined reference. Example: ` + "`" + `name,price,category.name` + "`" + `",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "html",
+                            "markdown",
+                            "text"
+                        ],
+                        "type": "string",
+                        "default": "html",
+                        "description": "Representation of RICH_TEXT properties: sanitised ` + "`" + `html` + "`" + `, the ` + "`" + `markdown` + "`" + ` source or plain ` + "`" + `text` + "`" + `. An HTML source is returned for ` + "`" + `markdown` + "`" + `",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nodes referencing this node, listed under ` + "`" + `_related` + "`" + ` by ` + "`" + `\u003ctypeId\u003e.\u003cproperty\u003e` + "`" + `: ` + "`" + `true` + "`" + ` for every reference, or comma-separated ` + "`" + `\u003ctypeId\u003e` + "`" + ` / ` + "`" + `\u003ctypeId\u003e.\u003cproperty\u003e` + "`" + `. Example: ` + "`" + `product.category` + "`" + `",
+                        "name": "includeReverse",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -189,8 +1018,9 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Update node information",
+                "description": "Update node information from a JSON object or multipart form data.\nOnly the properties sent are changed, an explicit JSON ` + "`" + `null` + "`" + ` clears the property.\nA ` + "`" + `_json` + "`" + ` part holding a JSON object may be sent alongside multipart form fields.",
                 "consumes": [
+                    "application/json",
                     "multipart/form-data"
                 ],
                 "produces": [
@@ -232,6 +1062,12 @@ const docTemplate = `{
                         "description": "Image file",
                         "name": "image",
                         "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON object merged with the form fields",
+                        "name": "_json",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -243,11 +1079,30 @@ const docTemplate = `{
                     },
                     "404": {
                         "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "concurrent writes kept taking the slug",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "unsupported content type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "{ error: \\\"validation failed\\\", fields: [{ field, rule, message }] }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             },
             "delete": {
-                "description": "Soft delete (mark as deleted) a specific node by setting deleted_at/deleted_by",
+                "description": "Soft delete (mark as deleted) a specific node by setting deleted_at/deleted_by.\nReferences to it declaring ` + "`" + `onDelete` + "`" + ` are applied: ` + "`" + `restrict` + "`" + ` blocks the delete, ` + "`" + `cascade` + "`" + ` deletes the\nreferencing nodes and ` + "`" + `setNull` + "`" + ` clears the reference.",
                 "consumes": [
                     "application/json"
                 ],
@@ -283,13 +1138,138 @@ const docTemplate = `{
                     },
                     "404": {
                         "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "a restrict reference blocks the delete",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/{typeId}/{id}/related/{relatedTypeId}": {
+            "get": {
+                "description": "List the nodes of ` + "`" + `relatedTypeId` + "`" + ` whose ` + "`" + `REFERENCE` + "`" + ` or ` + "`" + `REFERENCES` + "`" + ` property points at the node,\ne.g. ` + "`" + `GET /productCategory/{id}/related/product?via=category` + "`" + `. ` + "`" + `via` + "`" + ` names the property and can be\nleft out when ` + "`" + `relatedTypeId` + "`" + ` references the type through a single property.\nFiltering, sorting, pagination and projection work as on the list endpoint of ` + "`" + `relatedTypeId` + "`" + `.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "NodeType"
+                ],
+                "summary": "List related nodes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type ID",
+                        "name": "typeId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Node ID or slug",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Type ID of the referencing nodes",
+                        "name": "relatedTypeId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Property of relatedTypeId referencing typeId",
+                        "name": "via",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Keyset pagination cursor, see the list endpoint",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Set to false to skip counting ` + "`" + `total` + "`" + ` and ` + "`" + `totalPage` + "`" + `",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort expression, see the list endpoint",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, see the list endpoint",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated reference paths to expand",
+                        "name": "referenceView",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{ items: [...], pagination: {...} }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "node or related type not found",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
         "/{typeId}/{id}/restore": {
             "post": {
-                "description": "Soft-restore a previously soft-deleted node by clearing ` + "`" + `deleted_at` + "`" + ` and ` + "`" + `deleted_by` + "`" + `.",
+                "description": "Soft-restore a previously soft-deleted node by clearing ` + "`" + `deleted_at` + "`" + ` and ` + "`" + `deleted_by` + "`" + `.\nNodes deleted together with it through ` + "`" + `onDelete: cascade` + "`" + ` are restored as well.",
                 "consumes": [
                     "application/json"
                 ],
@@ -340,7 +1320,115 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "409": {
+                        "description": "the node references a deleted node",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "shared_dto.EnumOptionDTO": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "order": {
+                    "type": "integer"
+                },
+                "renamedFrom": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "shared_dto.NodeTypeDTO": {
+            "type": "object",
+            "properties": {
+                "propertyTypes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/shared_dto.PropertyTypeDTO"
+                    }
+                },
+                "tid": {
+                    "type": "string"
+                }
+            }
+        },
+        "shared_dto.PropertyTypeDTO": {
+            "type": "object",
+            "properties": {
+                "enum": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "jsonSchema": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "max": {
+                    "type": "number"
+                },
+                "maxLength": {
+                    "type": "integer"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "minLength": {
+                    "type": "integer"
+                },
+                "onDelete": {
+                    "type": "string"
+                },
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/shared_dto.EnumOptionDTO"
                     }
+                },
+                "pattern": {
+                    "type": "string"
+                },
+                "pid": {
+                    "type": "string"
+                },
+                "referenceType": {
+                    "type": "string"
+                },
+                "referenceValue": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "searchWeight": {
+                    "type": "string"
+                },
+                "searchable": {
+                    "type": "boolean"
+                },
+                "slugOnUpdate": {
+                    "type": "boolean"
+                },
+                "slugSource": {
+                    "type": "string"
+                },
+                "valueType": {
+                    "type": "string"
                 }
             }
         }
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireBearerToken only lets requests through whose Authorization header carries the given bearer token.
+// An empty token disables the routes behind it.
+func RequireBearerToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(token) == 0 {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"message": "ADMIN_TOKEN is not configured"})
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		provided, found := strings.CutPrefix(header, "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(provided)), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,9 +1,12 @@
 package helper_handler
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
 	"github.com/ledaian41/go-cms-service/pkg/shared/interface"
+	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
 	"log"
 	"net/http"
 )
@@ -19,16 +22,17 @@ func NewHelperHandler(nodeTypeService shared_interface.NodeTypeService, helperSe
 
 func (h *HelperHandler) LoadSchema(c *gin.Context) {
 	filePath := c.Query("filePath")
+	force := c.Query("force") == "true"
 	messageCh := make(chan string)
-	go h.helperService.LoadSchema(filePath, messageCh)
+	go h.helperService.LoadSchema(filePath, force, messageCh)
 	// Server-Sent Events (SSE) - Config Header streaming message
 	c.Writer.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
 	c.Writer.Header().Set("Connection", "keep-alive")
 	c.Status(http.StatusOK)
 
-	for tid := range messageCh {
-		_, err := c.Writer.Write([]byte(fmt.Sprintf("🎉 Load nodeType: %s successfully!\n", tid)))
+	for message := range messageCh {
+		_, err := c.Writer.Write([]byte(message + "\n"))
 		if err != nil {
 			log.Printf("❌ Error writing to response: %v", err)
 			break
@@ -37,6 +41,15 @@ func (h *HelperHandler) LoadSchema(c *gin.Context) {
 	}
 }
 
+func (h *HelperHandler) PlanSchema(c *gin.Context) {
+	plans, err := h.helperService.PlanSchema(c.Query("filePath"), c.Query("force") == "true")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": plans})
+}
+
 func (h *HelperHandler) LoadData(c *gin.Context) {
 	filePath := c.Query("filePath")
 	messageCh := make(chan string)
@@ -71,3 +84,44 @@ func (h *HelperHandler) DeleteNodeType(c *gin.Context) {
 	}
 	c.String(http.StatusOK, fmt.Sprintf("🎉 Delete nodeType: %s successfully!", tid))
 }
+
+func (h *HelperHandler) FetchVersions(c *gin.Context) {
+	versions, err := h.helperService.FetchVersions(c.Query("typeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": versions})
+}
+
+func (h *HelperHandler) DiffVersions(c *gin.Context) {
+	from := shared_utils.ParseInt(c.Query("from"))
+	to := shared_utils.ParseInt(c.Query("to"))
+	diff, err := h.helperService.DiffVersions(c.Query("typeId"), from, to)
+	if err != nil {
+		if errors.Is(err, shared_dto.ErrVersionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *HelperHandler) Rollback(c *gin.Context) {
+	version := shared_utils.ParseInt(c.Query("version"))
+	plan, err := h.helperService.Rollback(c.Query("typeId"), version, c.Query("force") == "true")
+	if err != nil {
+		switch {
+		case errors.Is(err, shared_dto.ErrVersionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, shared_dto.ErrDataLoss):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "plan": plan})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "plan": plan})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "success", "plan": plan})
+}
@@ -0,0 +1,251 @@
+package helper_handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+)
+
+type schemaOptions struct {
+	force  bool
+	dryRun bool
+}
+
+func readSchemaOptions(c *gin.Context) schemaOptions {
+	return schemaOptions{force: c.Query("force") == "true", dryRun: c.Query("dryRun") == "true"}
+}
+
+func respondSchemaChange(c *gin.Context, status int, opts schemaOptions, plan *shared_dto.SchemaPlanDTO, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, shared_dto.ErrNodeTypeNotFound), errors.Is(err, shared_dto.ErrPropertyTypeNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, shared_dto.ErrNodeTypeExists), errors.Is(err, shared_dto.ErrPropertyTypeExists),
+			errors.Is(err, shared_dto.ErrNodeTypeReferenced):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, shared_dto.ErrDataLoss):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "plan": plan})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "plan": plan})
+		}
+		return
+	}
+	if opts.dryRun {
+		c.JSON(http.StatusOK, gin.H{"message": "dry run", "plan": plan})
+		return
+	}
+	c.JSON(status, gin.H{"message": "success", "plan": plan})
+}
+
+// CreateNodeType godoc
+// @Summary Create node type
+// @Description Create a node type and its table from a JSON definition, the same shape as a schema file.
+// @Tags Schema
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param definition body shared_dto.NodeTypeDTO true "Node type definition"
+// @Param force query bool false "Apply changes that lose data"
+// @Param dryRun query bool false "Only return the migration plan"
+// @Success 201 {object} map[string]interface{} "{ message, plan }"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "node type exists"
+// @Router /schema/nodeType [post]
+func (h *HelperHandler) CreateNodeType(c *gin.Context) {
+	var nodeType node_type_model.NodeType
+	if err := c.ShouldBindJSON(&nodeType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts := readSchemaOptions(c)
+	plan, err := h.helperService.CreateNodeType(&nodeType, opts.force, opts.dryRun)
+	respondSchemaChange(c, http.StatusCreated, opts, plan, err)
+}
+
+// ReplaceNodeType godoc
+// @Summary Replace node type
+// @Description Replace the whole definition of a node type, properties missing from the body are removed.
+// @Description The node type is created when it does not exist. Declare `renamedFrom` on a property to rename it.
+// @Tags Schema
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tid path string true "Type ID"
+// @Param definition body shared_dto.NodeTypeDTO true "Node type definition"
+// @Param force query bool false "Apply changes that lose data"
+// @Param dryRun query bool false "Only return the migration plan"
+// @Success 200 {object} map[string]interface{} "{ message, plan }"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "{ error, plan } when data would be lost"
+// @Router /schema/nodeType/{tid} [put]
+func (h *HelperHandler) ReplaceNodeType(c *gin.Context) {
+	var nodeType node_type_model.NodeType
+	if err := c.ShouldBindJSON(&nodeType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts := readSchemaOptions(c)
+	plan, err := h.helperService.ReplaceNodeType(c.Param("tid"), &nodeType, opts.force, opts.dryRun)
+	respondSchemaChange(c, http.StatusOK, opts, plan, err)
+}
+
+// PatchNodeType godoc
+// @Summary Patch node type
+// @Description Add or replace the given property types by pid, the other properties are kept.
+// @Tags Schema
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tid path string true "Type ID"
+// @Param definition body shared_dto.NodeTypeDTO true "Property types to add or replace"
+// @Param force query bool false "Apply changes that lose data"
+// @Param dryRun query bool false "Only return the migration plan"
+// @Success 200 {object} map[string]interface{} "{ message, plan }"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "{ error, plan } when data would be lost"
+// @Router /schema/nodeType/{tid} [patch]
+func (h *HelperHandler) PatchNodeType(c *gin.Context) {
+	var nodeType node_type_model.NodeType
+	if err := c.ShouldBindJSON(&nodeType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts := readSchemaOptions(c)
+	plan, err := h.helperService.PatchNodeType(c.Param("tid"), nodeType.PropertyTypes, opts.force, opts.dryRun)
+	respondSchemaChange(c, http.StatusOK, opts, plan, err)
+}
+
+// DropNodeType godoc
+// @Summary Delete node type
+// @Description Drop the table of a node type and delete its definition, refused while the table has rows unless force=true
+// @Description and while other node types reference it.
+// @Tags Schema
+// @Produce json
+// @Security BearerAuth
+// @Param tid path string true "Type ID"
+// @Param force query bool false "Drop the table even if it has rows"
+// @Param dryRun query bool false "Only return the migration plan"
+// @Success 200 {object} map[string]interface{} "{ message, plan }"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "{ error, plan } when the table has rows, { error } when the node type is referenced"
+// @Router /schema/nodeType/{tid} [delete]
+func (h *HelperHandler) DropNodeType(c *gin.Context) {
+	opts := readSchemaOptions(c)
+	plan, err := h.helperService.DropNodeType(c.Param("tid"), opts.force, opts.dryRun)
+	respondSchemaChange(c, http.StatusOK, opts, plan, err)
+}
+
+// AddPropertyType godoc
+// @Summary Add property type
+// @Description Add a property type to a node type and its column to the table.
+// @Tags Schema
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tid path string true "Type ID"
+// @Param definition body shared_dto.PropertyTypeDTO true "Property type definition"
+// @Param force query bool false "Apply changes that lose data"
+// @Param dryRun query bool false "Only return the migration plan"
+// @Success 201 {object} map[string]interface{} "{ message, plan }"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "{ error } when the property type exists, { error, plan } when data would be lost"
+// @Router /schema/nodeType/{tid}/propertyType [post]
+func (h *HelperHandler) AddPropertyType(c *gin.Context) {
+	var pt node_type_model.PropertyType
+	if err := c.ShouldBindJSON(&pt); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts := readSchemaOptions(c)
+	plan, err := h.helperService.AddPropertyType(c.Param("tid"), &pt, opts.force, opts.dryRun)
+	respondSchemaChange(c, http.StatusCreated, opts, plan, err)
+}
+
+// ReplacePropertyType godoc
+// @Summary Replace property type
+// @Description Replace the definition of a property type. A different pid in the body renames the property and its column.
+// @Tags Schema
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tid path string true "Type ID"
+// @Param pid path string true "Property ID"
+// @Param definition body shared_dto.PropertyTypeDTO true "Property type definition"
+// @Param force query bool false "Apply changes that lose data"
+// @Param dryRun query bool false "Only return the migration plan"
+// @Success 200 {object} map[string]interface{} "{ message, plan }"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "{ error, plan } when data would be lost"
+// @Router /schema/nodeType/{tid}/propertyType/{pid} [put]
+func (h *HelperHandler) ReplacePropertyType(c *gin.Context) {
+	var pt node_type_model.PropertyType
+	if err := c.ShouldBindJSON(&pt); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts := readSchemaOptions(c)
+	plan, err := h.helperService.ReplacePropertyType(c.Param("tid"), c.Param("pid"), &pt, opts.force, opts.dryRun)
+	respondSchemaChange(c, http.StatusOK, opts, plan, err)
+}
+
+// PatchPropertyType godoc
+// @Summary Patch property type
+// @Description Change only the given attributes of a property type, e.g. `{"required": true}`.
+// @Tags Schema
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tid path string true "Type ID"
+// @Param pid path string true "Property ID"
+// @Param definition body map[string]interface{} true "Attributes to change"
+// @Param force query bool false "Apply changes that lose data"
+// @Param dryRun query bool false "Only return the migration plan"
+// @Success 200 {object} map[string]interface{} "{ message, plan }"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "{ error, plan } when data would be lost"
+// @Router /schema/nodeType/{tid}/propertyType/{pid} [patch]
+func (h *HelperHandler) PatchPropertyType(c *gin.Context) {
+	var patch map[string]interface{}
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts := readSchemaOptions(c)
+	plan, err := h.helperService.PatchPropertyType(c.Param("tid"), c.Param("pid"), patch, opts.force, opts.dryRun)
+	respondSchemaChange(c, http.StatusOK, opts, plan, err)
+}
+
+// DeletePropertyType godoc
+// @Summary Delete property type
+// @Description Remove a property type and drop its column, refused while the column holds values unless force=true.
+// @Tags Schema
+// @Produce json
+// @Security BearerAuth
+// @Param tid path string true "Type ID"
+// @Param pid path string true "Property ID"
+// @Param force query bool false "Drop the column even if it holds values"
+// @Param dryRun query bool false "Only return the migration plan"
+// @Success 200 {object} map[string]interface{} "{ message, plan }"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "{ error, plan } when the column holds values"
+// @Router /schema/nodeType/{tid}/propertyType/{pid} [delete]
+func (h *HelperHandler) DeletePropertyType(c *gin.Context) {
+	opts := readSchemaOptions(c)
+	plan, err := h.helperService.DeletePropertyType(c.Param("tid"), c.Param("pid"), opts.force, opts.dryRun)
+	respondSchemaChange(c, http.StatusOK, opts, plan, err)
+}
@@ -0,0 +1,231 @@
+package helper_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/ledaian41/go-cms-service/pkg/helper/sql_helper"
+	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"gorm.io/gorm"
+)
+
+// submitNodeType plans nodeType and applies the plan unless dryRun is set, the plan is returned in both cases.
+func (s *HelperService) submitNodeType(nodeType *node_type_model.NodeType, force, dryRun bool, note string) (*shared_dto.SchemaPlanDTO, error) {
+	plan, err := s.planNodeType(nodeType, force)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return &plan.SchemaPlanDTO, nil
+	}
+	if err := s.applyPlan(plan, note); err != nil {
+		return &plan.SchemaPlanDTO, err
+	}
+	s.tableColumnCache.Delete(strcase.ToSnake(nodeType.TID))
+	return &plan.SchemaPlanDTO, nil
+}
+
+func (s *HelperService) fetchStoredNodeType(tid string) (*node_type_model.NodeType, error) {
+	var existing node_type_model.NodeType
+	err := s.db.Preload("PropertyTypes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	}).Where("tid = ?", tid).First(&existing).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", shared_dto.ErrNodeTypeNotFound, tid)
+		}
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// editNodeType applies edit to a copy of the stored definition of tid and submits the result.
+func (s *HelperService) editNodeType(tid string, force, dryRun bool, note string, edit func(nodeType *node_type_model.NodeType) error) (*shared_dto.SchemaPlanDTO, error) {
+	existing, err := s.fetchStoredNodeType(strcase.ToLowerCamel(tid))
+	if err != nil {
+		return nil, err
+	}
+	nodeType, err := node_type_model.NodeTypeFromDTO(existing.NodeTypeDTO())
+	if err != nil {
+		return nil, err
+	}
+	if err := edit(nodeType); err != nil {
+		return nil, err
+	}
+	return s.submitNodeType(nodeType, force, dryRun, note)
+}
+
+func (s *HelperService) CreateNodeType(nodeType *node_type_model.NodeType, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error) {
+	nodeType.TID = strcase.ToLowerCamel(nodeType.TID)
+	var count int64
+	if err := s.db.Model(&node_type_model.NodeType{}).Where("tid = ?", nodeType.TID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("%w: %s", shared_dto.ErrNodeTypeExists, nodeType.TID)
+	}
+	return s.submitNodeType(nodeType, force, dryRun, "api: create nodeType")
+}
+
+// ReplaceNodeType replaces the whole definition of tid, creating the node type when it does not exist yet.
+func (s *HelperService) ReplaceNodeType(tid string, nodeType *node_type_model.NodeType, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error) {
+	tid = strcase.ToLowerCamel(tid)
+	if len(nodeType.TID) > 0 && strcase.ToLowerCamel(nodeType.TID) != tid {
+		return nil, fmt.Errorf("tid %s of the definition does not match %s", nodeType.TID, tid)
+	}
+	nodeType.TID = tid
+	return s.submitNodeType(nodeType, force, dryRun, "api: replace nodeType")
+}
+
+// PatchNodeType adds or replaces the given property types by pid and keeps the other ones.
+// A property declaring renamedFrom replaces the property it is renamed from.
+func (s *HelperService) PatchNodeType(tid string, propertyTypes []*node_type_model.PropertyType, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error) {
+	return s.editNodeType(tid, force, dryRun, "api: patch nodeType", func(nodeType *node_type_model.NodeType) error {
+		for _, pt := range propertyTypes {
+			if len(pt.RenamedFrom) > 0 && nodeType.FindPropertyType(pt.PID) == nil && nodeType.ReplacePropertyType(pt.RenamedFrom, pt) {
+				continue
+			}
+			nodeType.SetPropertyType(pt)
+		}
+		return nil
+	})
+}
+
+// DropNodeType drops the table of tid and deletes its definition. The schema history is kept.
+func (s *HelperService) DropNodeType(tid string, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error) {
+	existing, err := s.fetchStoredNodeType(strcase.ToLowerCamel(tid))
+	if err != nil {
+		return nil, err
+	}
+
+	referrers, err := s.fetchReferrers(existing)
+	if err != nil {
+		return nil, err
+	}
+	if len(referrers) > 0 {
+		return nil, fmt.Errorf("%w: %s by %s", shared_dto.ErrNodeTypeReferenced, existing.TID, strings.Join(referrers, ", "))
+	}
+
+	table := strcase.ToSnake(existing.TID)
+	plan := &schemaPlan{
+		SchemaPlanDTO: shared_dto.SchemaPlanDTO{TID: existing.TID, Action: "delete", Risk: shared_dto.RiskNone, Statements: []shared_dto.SchemaStatementDTO{}},
+		nodeType:      existing,
+		existing:      existing,
+	}
+	if s.db.Migrator().HasTable(table) {
+		count, err := s.countRows(fmt.Sprintf("SELECT COUNT(*) FROM %s", sql_helper.QuoteIdentifier(table)))
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 && !force {
+			plan.Block(fmt.Sprintf("table %s still has %d rows, delete with force=true to drop them", table, count))
+		}
+		plan.AddStatement(fmt.Sprintf("DROP TABLE IF EXISTS %s;", sql_helper.QuoteIdentifier(table)), fmt.Sprintf("drop table %s", table), lossRisk(count), count)
+	}
+	if dryRun {
+		return &plan.SchemaPlanDTO, nil
+	}
+	if err := s.applyPlan(plan, ""); err != nil {
+		return &plan.SchemaPlanDTO, err
+	}
+	s.tableColumnCache.Delete(table)
+	log.Printf("🎉 Helper - Drop %s schema successfully!", existing.TID)
+	return &plan.SchemaPlanDTO, nil
+}
+
+// fetchReferrers returns the other node types with a REFERENCE or REFERENCES property to nodeType.
+func (s *HelperService) fetchReferrers(nodeType *node_type_model.NodeType) ([]string, error) {
+	var tids []string
+	err := s.db.Model(&node_type_model.PropertyType{}).
+		Joins("JOIN node_types ON node_types.id = property_types.node_type_refer AND node_types.deleted_at IS NULL").
+		Where("property_types.reference_type = ? AND property_types.node_type_refer <> ?", nodeType.TID, nodeType.ID).
+		Distinct().Order("node_types.tid").Pluck("node_types.tid", &tids).Error
+	return tids, err
+}
+
+func (s *HelperService) AddPropertyType(tid string, pt *node_type_model.PropertyType, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error) {
+	return s.editNodeType(tid, force, dryRun, fmt.Sprintf("api: add property %s", pt.PID), func(nodeType *node_type_model.NodeType) error {
+		if nodeType.FindPropertyType(pt.PID) != nil {
+			return fmt.Errorf("%w: %s.%s", shared_dto.ErrPropertyTypeExists, nodeType.TID, pt.PID)
+		}
+		nodeType.PropertyTypes = append(nodeType.PropertyTypes, pt)
+		return nil
+	})
+}
+
+// ReplacePropertyType replaces the definition of pid. A different pid in the definition renames the property.
+func (s *HelperService) ReplacePropertyType(tid, pid string, pt *node_type_model.PropertyType, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error) {
+	return s.editNodeType(tid, force, dryRun, fmt.Sprintf("api: replace property %s", pid), func(nodeType *node_type_model.NodeType) error {
+		return replacePropertyType(nodeType, pid, pt)
+	})
+}
+
+// PatchPropertyType merges the given attributes into the definition of pid.
+func (s *HelperService) PatchPropertyType(tid, pid string, patch map[string]interface{}, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error) {
+	return s.editNodeType(tid, force, dryRun, fmt.Sprintf("api: patch property %s", pid), func(nodeType *node_type_model.NodeType) error {
+		current := nodeType.FindPropertyType(pid)
+		if current == nil {
+			return fmt.Errorf("%w: %s.%s", shared_dto.ErrPropertyTypeNotFound, nodeType.TID, pid)
+		}
+		pt, err := mergePropertyType(current, patch)
+		if err != nil {
+			return err
+		}
+		return replacePropertyType(nodeType, pid, pt)
+	})
+}
+
+func (s *HelperService) DeletePropertyType(tid, pid string, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error) {
+	return s.editNodeType(tid, force, dryRun, fmt.Sprintf("api: delete property %s", pid), func(nodeType *node_type_model.NodeType) error {
+		if !nodeType.RemovePropertyType(pid) {
+			return fmt.Errorf("%w: %s.%s", shared_dto.ErrPropertyTypeNotFound, nodeType.TID, pid)
+		}
+		return nil
+	})
+}
+
+func replacePropertyType(nodeType *node_type_model.NodeType, pid string, pt *node_type_model.PropertyType) error {
+	if nodeType.FindPropertyType(pid) == nil {
+		return fmt.Errorf("%w: %s.%s", shared_dto.ErrPropertyTypeNotFound, nodeType.TID, pid)
+	}
+	if len(pt.PID) == 0 {
+		pt.PID = pid
+	}
+	if pt.PID != pid {
+		if nodeType.FindPropertyType(pt.PID) != nil {
+			return fmt.Errorf("%w: %s.%s", shared_dto.ErrPropertyTypeExists, nodeType.TID, pt.PID)
+		}
+		pt.RenamedFrom = pid
+	}
+	nodeType.ReplacePropertyType(pid, pt)
+	return nil
+}
+
+// mergePropertyType overlays the JSON attributes of patch on the definition of current.
+func mergePropertyType(current *node_type_model.PropertyType, patch map[string]interface{}) (*node_type_model.PropertyType, error) {
+	data, err := json.Marshal(current.PropertyTypeDTO())
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]interface{})
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+	for key, value := range patch {
+		merged[key] = value
+	}
+
+	if data, err = json.Marshal(merged); err != nil {
+		return nil, err
+	}
+	var pt node_type_model.PropertyType
+	if err := json.Unmarshal(data, &pt); err != nil {
+		return nil, fmt.Errorf("invalid property definition: %w", err)
+	}
+	return &pt, nil
+}
@@ -0,0 +1,62 @@
+package helper_service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func productNodeType() *node_type_model.NodeType {
+	return &node_type_model.NodeType{
+		TID: "product",
+		PropertyTypes: []*node_type_model.PropertyType{
+			{PID: "name", ValueType: "STRING", Required: true},
+			{PID: "price", ValueType: "DOUBLE"},
+		},
+	}
+}
+
+func TestMergePropertyType(t *testing.T) {
+	nodeType := productNodeType()
+	pt, err := mergePropertyType(nodeType.FindPropertyType("name"), map[string]interface{}{"maxLength": 80})
+	assert.NoError(t, err)
+	assert.Equal(t, "name", pt.PID)
+	assert.Equal(t, "STRING", pt.ValueType)
+	assert.True(t, pt.Required)
+	assert.Equal(t, 80, *pt.MaxLength)
+
+	_, err = mergePropertyType(nodeType.FindPropertyType("name"), map[string]interface{}{"required": "yes"})
+	assert.Error(t, err)
+}
+
+func TestReplacePropertyType(t *testing.T) {
+	nodeType := productNodeType()
+	assert.NoError(t, replacePropertyType(nodeType, "name", &node_type_model.PropertyType{PID: "title", ValueType: "STRING"}))
+	assert.Equal(t, "title", nodeType.PropertyTypes[0].PID)
+	assert.Equal(t, "name", nodeType.PropertyTypes[0].RenamedFrom)
+
+	err := replacePropertyType(nodeType, "title", &node_type_model.PropertyType{PID: "price", ValueType: "STRING"})
+	assert.True(t, errors.Is(err, shared_dto.ErrPropertyTypeExists))
+
+	err = replacePropertyType(nodeType, "missing", &node_type_model.PropertyType{ValueType: "STRING"})
+	assert.True(t, errors.Is(err, shared_dto.ErrPropertyTypeNotFound))
+}
+
+func TestMergePropertyType_Options(t *testing.T) {
+	status := &node_type_model.PropertyType{PID: "status", ValueType: "ENUM", Options: []shared_dto.EnumOptionDTO{
+		{Value: "published", Label: "Published", Order: 2, Color: "#2e7d32"},
+		{Value: "draft", Order: 1},
+	}}
+
+	pt, err := mergePropertyType(status, map[string]interface{}{"options": []interface{}{
+		map[string]interface{}{"value": "live", "label": "Live", "renamedFrom": "published"},
+		map[string]interface{}{"value": "draft"},
+	}})
+	assert.NoError(t, err)
+	assert.NoError(t, pt.Validate())
+	assert.Equal(t, "published", pt.Options[0].RenamedFrom)
+	assert.Empty(t, pt.PropertyTypeDTO().Options[0].RenamedFrom)
+}
@@ -0,0 +1,594 @@
+package helper_service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/ledaian41/go-cms-service/pkg/helper/sql_helper"
+	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+	"gorm.io/gorm"
+)
+
+type propertyTypeUpdate struct {
+	current *node_type_model.PropertyType
+	next    *node_type_model.PropertyType
+}
+
+// schemaPlan holds the DDL statements of a schema load together with the metadata changes applied after them.
+type schemaPlan struct {
+	shared_dto.SchemaPlanDTO
+	nodeType *node_type_model.NodeType
+	existing *node_type_model.NodeType
+	creates  []*node_type_model.PropertyType
+	updates  []propertyTypeUpdate
+	deletes  []*node_type_model.PropertyType
+	renames  []shared_dto.SchemaRenameDTO
+	// richTexts are the RICH_TEXT properties converted from text, whose values are rendered after the statements.
+	richTexts []string
+	// searchRebuilt is set when the search column, and so its index, is dropped to be added again.
+	searchRebuilt bool
+}
+
+// hasStatement reports whether the plan already executes sql.
+func (plan *schemaPlan) hasStatement(sql string) bool {
+	for _, statement := range plan.Statements {
+		if statement.SQL == sql {
+			return true
+		}
+	}
+	return false
+}
+
+type liveColumn struct {
+	ColumnName string
+	DataType   string
+	UdtName    string
+}
+
+// planNodeType diffs nodeType against the stored definition and the live table without changing anything.
+func (s *HelperService) planNodeType(nodeType *node_type_model.NodeType, force bool) (*schemaPlan, error) {
+	if err := nodeType.Validate(); err != nil {
+		return nil, err
+	}
+	for _, pt := range nodeType.PropertyTypes {
+		vt, err := value_type.ParseValueType(pt.ValueType)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", pt.PID, err)
+		}
+		if pt.Searchable && !vt.Searchable() {
+			return nil, fmt.Errorf("property %s: %s properties cannot be searchable", pt.PID, vt)
+		}
+	}
+
+	table := strcase.ToSnake(nodeType.TID)
+	plan := &schemaPlan{
+		SchemaPlanDTO: shared_dto.SchemaPlanDTO{TID: nodeType.TID, Risk: shared_dto.RiskNone, Statements: []shared_dto.SchemaStatementDTO{}},
+		nodeType:      nodeType,
+	}
+
+	var existing node_type_model.NodeType
+	if err := s.db.Preload("PropertyTypes").Where("tid = ?", nodeType.TID).First(&existing).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		plan.Action = "create"
+		plan.AddStatement(sql_helper.QueryCreateNewTable(nodeType), fmt.Sprintf("create table %s", table), shared_dto.RiskNone, 0)
+		if searchExpression := sql_helper.SearchExpression(nodeType.PropertyTypes); len(searchExpression) > 0 {
+			plan.AddStatement(sql_helper.QueryAddSearchColumn(table, searchExpression), "add search column", shared_dto.RiskNone, 0)
+		}
+		if err := s.planIndexes(plan, table, false); err != nil {
+			return nil, err
+		}
+		if err := s.planForeignKeys(plan, table, false, force); err != nil {
+			return nil, err
+		}
+		return plan, nil
+	}
+
+	plan.Action = "update"
+	plan.existing = &existing
+
+	liveColumns, err := s.getLiveColumns(table)
+	if err != nil {
+		return nil, err
+	}
+	if len(liveColumns) == 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("table %s does not exist and will be created", table))
+		plan.AddStatement(sql_helper.QueryCreateNewTable(nodeType), fmt.Sprintf("create table %s", table), shared_dto.RiskNone, 0)
+	}
+
+	// The generated search column depends on the searchable columns, it is dropped before they change.
+	searchExpression := sql_helper.SearchExpression(nodeType.PropertyTypes)
+	_, liveSearch := liveColumns[sql_helper.SearchColumn]
+	if liveSearch && (len(searchExpression) == 0 || searchExpression != sql_helper.SearchExpression(existing.PropertyTypes)) {
+		plan.AddStatement(sql_helper.QueryDeleteColumnFromTable(table, sql_helper.SearchColumn)+";", "drop search column", shared_dto.RiskNone, 0)
+		plan.searchRebuilt = true
+		liveSearch = false
+	}
+
+	currentMap := make(map[string]*node_type_model.PropertyType)
+	for _, pt := range existing.PropertyTypes {
+		currentMap[pt.PID] = pt
+	}
+
+	declared := make(map[string]bool)
+	for _, pt := range nodeType.PropertyTypes {
+		declared[pt.PID] = true
+	}
+
+	keptPIDs := make(map[string]bool)
+	for _, pt := range nodeType.PropertyTypes {
+		pt.NodeTypeRefer = existing.ID
+		keptPIDs[pt.PID] = true
+		current := currentMap[pt.PID]
+		if current == nil && len(pt.RenamedFrom) > 0 {
+			if renamed := currentMap[pt.RenamedFrom]; renamed != nil && !declared[pt.RenamedFrom] {
+				current = renamed
+				keptPIDs[pt.RenamedFrom] = true
+				planRename(plan, table, renamed, pt, liveColumns)
+			}
+		}
+		if len(liveColumns) > 0 {
+			if err := s.planColumn(plan, table, current, pt, liveColumns, force); err != nil {
+				return nil, err
+			}
+			if err := s.planEnumOptions(plan, table, pt, liveColumns, force); err != nil {
+				return nil, err
+			}
+			if err := s.planSlug(plan, table, current, pt, liveColumns); err != nil {
+				return nil, err
+			}
+		}
+		if current == nil {
+			plan.creates = append(plan.creates, pt)
+		} else {
+			plan.updates = append(plan.updates, propertyTypeUpdate{current: current, next: pt})
+		}
+	}
+
+	removedPIDs := make([]string, 0)
+	for pid := range currentMap {
+		if !keptPIDs[pid] {
+			removedPIDs = append(removedPIDs, pid)
+		}
+	}
+	sort.Strings(removedPIDs)
+	for _, pid := range removedPIDs {
+		plan.deletes = append(plan.deletes, currentMap[pid])
+		if _, exists := liveColumns[strcase.ToSnake(pid)]; !exists {
+			continue
+		}
+		count, err := s.countRows(sql_helper.QueryCountNotNull(table, pid))
+		if err != nil {
+			return nil, err
+		}
+		planDropColumn(plan, table, pid, fmt.Sprintf("drop column %s", strcase.ToSnake(pid)), count, force)
+	}
+
+	if len(searchExpression) > 0 && !liveSearch {
+		plan.AddStatement(sql_helper.QueryAddSearchColumn(table, searchExpression), "add search column", shared_dto.RiskNone, 0)
+	}
+
+	if err := s.planIndexes(plan, table, len(liveColumns) > 0); err != nil {
+		return nil, err
+	}
+	if err := s.planForeignKeys(plan, table, len(liveColumns) > 0, force); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// planColumn adds the statements bringing the live column of pt in line with its new definition.
+func (s *HelperService) planColumn(plan *schemaPlan, table string, current, pt *node_type_model.PropertyType, liveColumns map[string]string, force bool) error {
+	column := strcase.ToSnake(pt.PID)
+	desiredType := value_type.MapValueTypeToSQL(pt)
+	if len(desiredType) == 0 {
+		return nil
+	}
+
+	liveType, exists := liveColumns[column]
+	if !exists {
+		if current != nil {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("column %s is missing and will be added again", column))
+		}
+		plan.AddStatement(sql_helper.QueryAddColumnToTable(table, pt), fmt.Sprintf("add column %s %s", column, desiredType), shared_dto.RiskNone, 0)
+		return nil
+	}
+	if liveType == desiredType {
+		return nil
+	}
+
+	var previous value_type.ValueType
+	if current != nil {
+		previous = value_type.ValueType(current.ValueType)
+	}
+	cast, found := sql_helper.FindPropertyCast(liveType, previous, value_type.ValueType(pt.ValueType))
+	var lost int64
+	if query := sql_helper.QueryCountInvalidCast(table, pt.PID, cast, found); len(query) > 0 {
+		var err error
+		if lost, err = s.countRows(query); err != nil {
+			return err
+		}
+	}
+	if found {
+		if lost > 0 && !force {
+			plan.Block(fmt.Sprintf("%d rows of %s.%s cannot be converted from %s to %s, reload with force=true to discard them",
+				lost, table, column, liveType, desiredType))
+		}
+		description := fmt.Sprintf("change type of column %s from %s to %s", column, liveType, desiredType)
+		if lost > 0 {
+			description += fmt.Sprintf(", %d values will be set to NULL", lost)
+		}
+		if pt.ValueType == string(value_type.RichText) && liveType == "text" {
+			plan.richTexts = append(plan.richTexts, pt.PID)
+			description += ", values are then rendered to HTML and text"
+		}
+		if len(cast.Setup) > 0 && !plan.hasStatement(cast.Setup) {
+			plan.AddStatement(cast.Setup, fmt.Sprintf("create the function converting %s to %s", liveType, desiredType), shared_dto.RiskNone, 0)
+		}
+		plan.AddStatement(sql_helper.QueryAlterColumnType(table, pt.PID, cast, force), description, lossRisk(lost), lost)
+		return nil
+	}
+
+	// No conversion exists between the types, the column can only be recreated.
+	planDropColumn(plan, table, pt.PID, fmt.Sprintf("drop column %s, no conversion from %s to %s", column, liveType, desiredType), lost, force)
+	plan.AddStatement(sql_helper.QueryAddColumnToTable(table, pt), fmt.Sprintf("add column %s %s", column, desiredType), shared_dto.RiskNone, 0)
+	return nil
+}
+
+// planDropColumn drops the column of pid, which loses its count values and so requires force when it has any.
+func planDropColumn(plan *schemaPlan, table, pid, description string, count int64, force bool) {
+	if count > 0 && !force {
+		plan.Block(fmt.Sprintf("dropping column %s.%s would lose %d values, reload with force=true to drop it",
+			table, strcase.ToSnake(pid), count))
+	}
+	plan.AddStatement(sql_helper.QueryDeleteColumnFromTable(table, pid)+";", description, lossRisk(count), count)
+}
+
+// planEnumOptions moves the records of the renamed options of an ENUM property to their new value and
+// clears the values that are no longer options, which requires force.
+func (s *HelperService) planEnumOptions(plan *schemaPlan, table string, pt *node_type_model.PropertyType, liveColumns map[string]string, force bool) error {
+	column := strcase.ToSnake(pt.PID)
+	if pt.ValueType != string(value_type.Enum) || liveColumns[column] != "text" {
+		return nil
+	}
+
+	values := make([]string, 0, len(pt.Options))
+	known := make([]string, 0, len(pt.Options))
+	for _, option := range pt.Options {
+		values = append(values, option.Value)
+		known = append(known, option.Value)
+		if len(option.RenamedFrom) > 0 {
+			known = append(known, option.RenamedFrom)
+		}
+	}
+
+	for _, option := range pt.Options {
+		if len(option.RenamedFrom) == 0 {
+			continue
+		}
+		plan.renames = append(plan.renames, shared_dto.SchemaRenameDTO{PID: pt.PID, From: option.RenamedFrom, To: option.Value})
+		count, err := s.countRows(sql_helper.QueryCountEnumValue(table, pt.PID, option.RenamedFrom))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			plan.AddStatement(sql_helper.QueryRenameEnumValue(table, pt.PID, option.RenamedFrom, option.Value),
+				fmt.Sprintf("rename option %s of %s to %s", option.RenamedFrom, column, option.Value), shared_dto.RiskLow, count)
+		}
+	}
+
+	invalid, err := s.countRows(sql_helper.QueryCountInvalidEnum(table, pt.PID, known))
+	if err != nil {
+		return err
+	}
+	if invalid > 0 {
+		if !force {
+			plan.Block(fmt.Sprintf("%d rows of %s.%s hold values that are not options, rename them with renamedFrom or reload with force=true to clear them",
+				invalid, table, column))
+		}
+		plan.AddStatement(sql_helper.QueryClearInvalidEnum(table, pt.PID, values),
+			fmt.Sprintf("clear %d values of %s that are not options", invalid, column), lossRisk(invalid), invalid)
+	}
+	return nil
+}
+
+// planSlug fills a column becoming a SLUG before its unique index is created: the values are slugified, the
+// missing ones generated from the slug source and the repeated ones suffixed.
+func (s *HelperService) planSlug(plan *schemaPlan, table string, current, pt *node_type_model.PropertyType, liveColumns map[string]string) error {
+	if pt.ValueType != string(value_type.Slug) {
+		return nil
+	}
+	slugColumn := liveColumnOf(pt, liveColumns)
+	if current != nil && current.ValueType == pt.ValueType && liveColumns[slugColumn] == "text" {
+		return nil
+	}
+	var sourceColumn string
+	if source := plan.nodeType.FindPropertyType(pt.SlugSource); source != nil {
+		sourceColumn = liveColumnOf(source, liveColumns)
+	}
+
+	var rows []slugRow
+	if err := s.db.Raw(sql_helper.QuerySlugRows(table, slugColumn, sourceColumn, liveColumns[sourceColumn])).Scan(&rows).Error; err != nil {
+		return err
+	}
+	if updates := slugUpdates(rows); len(updates) > 0 {
+		plan.AddStatement(sql_helper.QuerySetSlugs(table, pt.PID, updates),
+			fmt.Sprintf("set %d slugs of %s", len(updates), strcase.ToSnake(pt.PID)), shared_dto.RiskLow, int64(len(updates)))
+	}
+	return nil
+}
+
+// liveColumnOf returns the column holding the values of pt, under its previous name while it is being renamed,
+// empty when there is none yet.
+func liveColumnOf(pt *node_type_model.PropertyType, liveColumns map[string]string) string {
+	for _, pid := range []string{pt.PID, pt.RenamedFrom} {
+		if _, exists := liveColumns[strcase.ToSnake(pid)]; exists && len(pid) > 0 {
+			return strcase.ToSnake(pid)
+		}
+	}
+	return ""
+}
+
+type slugRow struct {
+	ID     string
+	Slug   *string
+	Source *string
+}
+
+// slugUpdates returns the slug of each row whose value changes: its value slugified, or its source when that is
+// empty, made unique with the rows before it keeping theirs.
+func slugUpdates(rows []slugRow) map[string]string {
+	slugs := make([]string, len(rows))
+	for i, row := range rows {
+		if row.Slug != nil {
+			slugs[i] = value_type.Slugify(*row.Slug)
+		}
+		if len(slugs[i]) == 0 && row.Source != nil {
+			slugs[i] = value_type.Slugify(*row.Source)
+		}
+	}
+	value_type.UniqueSlugs(slugs)
+
+	updates := make(map[string]string)
+	for i, row := range rows {
+		if (row.Slug == nil && len(slugs[i]) == 0) || (row.Slug != nil && *row.Slug == slugs[i]) {
+			continue
+		}
+		updates[row.ID] = slugs[i]
+	}
+	return updates
+}
+
+// planRename renames the column of current to the pid of pt and points the REFERENCE properties
+// of other node types showing the property to its new name.
+func planRename(plan *schemaPlan, table string, current, pt *node_type_model.PropertyType, liveColumns map[string]string) {
+	from, to := strcase.ToSnake(current.PID), strcase.ToSnake(pt.PID)
+	if liveType, exists := liveColumns[from]; exists {
+		if _, taken := liveColumns[to]; taken {
+			plan.Block(fmt.Sprintf("cannot rename column %s to %s, the column already exists", from, to))
+			return
+		}
+		plan.AddStatement(sql_helper.QueryRenameColumn(table, current.PID, pt.PID), fmt.Sprintf("rename column %s to %s", from, to), shared_dto.RiskNone, 0)
+		liveColumns[to] = liveType
+		delete(liveColumns, from)
+	}
+	plan.renames = append(plan.renames, shared_dto.SchemaRenameDTO{From: current.PID, To: pt.PID})
+	plan.AddStatement(sql_helper.QueryRenameReferenceValue(plan.nodeType.TID, current.PID, pt.PID),
+		fmt.Sprintf("point references to %s.%s at %s", plan.nodeType.TID, current.PID, pt.PID), shared_dto.RiskNone, 0)
+}
+
+// planIndexes creates the managed indexes the schema needs and drops the managed ones it no longer declares.
+func (s *HelperService) planIndexes(plan *schemaPlan, table string, tableExists bool) error {
+	liveIndexes := make(map[string]bool)
+	if tableExists {
+		var names []string
+		if err := s.db.Raw(sql_helper.QueryLiveIndexes(), table).Scan(&names).Error; err != nil {
+			return err
+		}
+		for _, name := range names {
+			liveIndexes[name] = true
+		}
+	}
+	if plan.searchRebuilt {
+		delete(liveIndexes, sql_helper.SearchIndex(table).Name)
+	}
+
+	desired := make(map[string]bool)
+	for _, index := range sql_helper.DesiredIndexes(plan.nodeType.TID, plan.nodeType.PropertyTypes) {
+		desired[index.Name] = true
+		if !liveIndexes[index.Name] {
+			plan.AddStatement(sql_helper.QueryCreateIndex(index), fmt.Sprintf("create %s index %s", index.Method, index.Name), shared_dto.RiskNone, 0)
+		}
+	}
+
+	stale := make([]string, 0)
+	for name := range liveIndexes {
+		if strings.HasPrefix(name, sql_helper.ManagedIndexPrefix(table)) && !desired[name] {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	for _, name := range stale {
+		plan.AddStatement(sql_helper.QueryDropIndex(name), fmt.Sprintf("drop index %s", name), shared_dto.RiskNone, 0)
+	}
+	return nil
+}
+
+type liveForeignKey struct {
+	Name     string
+	RefTable string
+	OnDelete string
+}
+
+// planForeignKeys adds the foreign keys of the REFERENCE properties declaring onDelete, replaces those whose
+// target or action changed and drops the managed ones the schema no longer declares.
+func (s *HelperService) planForeignKeys(plan *schemaPlan, table string, tableExists bool, force bool) error {
+	live := make(map[string]liveForeignKey)
+	if tableExists {
+		var foreignKeys []liveForeignKey
+		if err := s.db.Raw(sql_helper.QueryLiveForeignKeys(), table).Scan(&foreignKeys).Error; err != nil {
+			return err
+		}
+		for _, fk := range foreignKeys {
+			live[fk.Name] = fk
+		}
+	}
+
+	desired := make(map[string]bool)
+	for _, fk := range sql_helper.DesiredForeignKeys(plan.nodeType.TID, plan.nodeType.PropertyTypes) {
+		desired[fk.Name] = true
+		current, exists := live[fk.Name]
+		if exists && fk.Matches(current.RefTable, current.OnDelete) {
+			continue
+		}
+
+		if fk.RefTable != table {
+			refColumns, err := s.getLiveColumns(fk.RefTable)
+			if err != nil {
+				return err
+			}
+			if len(refColumns) == 0 {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("foreign key %s skipped, table %s does not exist yet; load the schema again once it does", fk.Name, fk.RefTable))
+				continue
+			}
+		}
+
+		if exists {
+			plan.AddStatement(sql_helper.QueryDropForeignKey(table, fk.Name), fmt.Sprintf("drop foreign key %s to replace it", fk.Name), shared_dto.RiskNone, 0)
+		}
+		if tableExists {
+			dangling, err := s.countRows(sql_helper.QueryCountDangling(fk))
+			if err != nil {
+				return err
+			}
+			if dangling > 0 {
+				if !force {
+					plan.Block(fmt.Sprintf("%d rows of %s.%s reference missing %s records, reload with force=true to clear them",
+						dangling, table, fk.Column, fk.RefTable))
+				}
+				plan.AddStatement(sql_helper.QueryClearDangling(fk),
+					fmt.Sprintf("clear %d values of %s referencing missing %s records", dangling, fk.Column, fk.RefTable), lossRisk(dangling), dangling)
+			}
+		}
+		plan.AddStatement(sql_helper.QueryAddForeignKey(fk), fmt.Sprintf("add foreign key %s on delete %s", fk.Name, fk.OnDelete), shared_dto.RiskNone, 0)
+	}
+
+	stale := make([]string, 0)
+	for name := range live {
+		if strings.HasPrefix(name, sql_helper.ManagedForeignKeyPrefix(table)) && !desired[name] {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	for _, name := range stale {
+		plan.AddStatement(sql_helper.QueryDropForeignKey(table, name), fmt.Sprintf("drop foreign key %s", name), shared_dto.RiskNone, 0)
+	}
+	return nil
+}
+
+// applyPlan executes the statements and the metadata changes of the plan in a single transaction.
+func (s *HelperService) applyPlan(plan *schemaPlan, note string) error {
+	if plan.Blocked {
+		return fmt.Errorf("%w: %s", shared_dto.ErrDataLoss, strings.Join(plan.Errors, "; "))
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range plan.Statements {
+			if err := tx.Exec(statement.SQL).Error; err != nil {
+				return fmt.Errorf("%s: %w", statement.Description, err)
+			}
+		}
+		for _, pid := range plan.richTexts {
+			if err := renderRichTexts(tx, plan.TID, pid); err != nil {
+				return fmt.Errorf("render %s values: %w", pid, err)
+			}
+		}
+
+		if plan.Action == "delete" {
+			if err := tx.Unscoped().Where("node_type_refer = ?", plan.existing.ID).Delete(&node_type_model.PropertyType{}).Error; err != nil {
+				return fmt.Errorf("delete PropertyTypes of %s: %w", plan.TID, err)
+			}
+			return tx.Unscoped().Delete(plan.existing).Error
+		}
+
+		if err := recordVersion(tx, plan, note); err != nil {
+			return fmt.Errorf("record schema version: %w", err)
+		}
+
+		if plan.existing == nil {
+			return tx.Create(plan.nodeType).Error
+		}
+
+		for _, update := range plan.updates {
+			update.current.PID = update.next.PID
+			update.current.AssignDefinition(update.next)
+			if err := tx.Save(update.current).Error; err != nil {
+				return fmt.Errorf("update PropertyType (pid=%s): %w", update.current.PID, err)
+			}
+		}
+		for _, pt := range plan.creates {
+			if err := tx.Create(pt).Error; err != nil {
+				return fmt.Errorf("create PropertyType (pid=%s): %w", pt.PID, err)
+			}
+		}
+		for _, pt := range plan.deletes {
+			if err := tx.Unscoped().Delete(pt).Error; err != nil {
+				return fmt.Errorf("delete PropertyType (pid=%s): %w", pt.PID, err)
+			}
+		}
+		return tx.Omit("PropertyTypes").Save(plan.existing).Error
+	})
+}
+
+// renderRichTexts stores the HTML and the plain text of the RICH_TEXT values of pid that only hold their source.
+func renderRichTexts(tx *gorm.DB, tid, pid string) error {
+	var rows []struct {
+		ID     string
+		Format string
+		Source string
+	}
+	if err := tx.Raw(sql_helper.QueryRichTextSources(tid, pid)).Scan(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		rt, err := value_type.NewRichText(row.Format, row.Source)
+		if err != nil {
+			return fmt.Errorf("record %s: %w", row.ID, err)
+		}
+		if err := tx.Exec(sql_helper.QueryUpdateRichText(tid, pid), rt, row.ID).Error; err != nil {
+			return fmt.Errorf("record %s: %w", row.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *HelperService) getLiveColumns(table string) (map[string]string, error) {
+	var columns []liveColumn
+	if err := s.db.Raw(sql_helper.QueryLiveColumns(), table).Scan(&columns).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(columns))
+	for _, c := range columns {
+		result[c.ColumnName] = sql_helper.NormalizeSQLType(c.DataType, c.UdtName)
+	}
+	return result, nil
+}
+
+func (s *HelperService) countRows(query string) (int64, error) {
+	var count int64
+	err := s.db.Raw(query).Scan(&count).Error
+	return count, err
+}
+
+func lossRisk(affectedRows int64) string {
+	if affectedRows > 0 {
+		return shared_dto.RiskHigh
+	}
+	return shared_dto.RiskLow
+}
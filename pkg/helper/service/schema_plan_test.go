@@ -0,0 +1,129 @@
+package helper_service
+
+import (
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/helper/sql_helper"
+	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPlan() *schemaPlan {
+	return &schemaPlan{
+		SchemaPlanDTO: shared_dto.SchemaPlanDTO{TID: "product", Risk: shared_dto.RiskNone},
+		nodeType:      productNodeType(),
+	}
+}
+
+func TestPlanDropColumn(t *testing.T) {
+	plan := newTestPlan()
+	planDropColumn(plan, "product", "oldPrice", "drop column old_price", 0, false)
+	assert.False(t, plan.Blocked)
+	assert.Equal(t, shared_dto.RiskLow, plan.Risk)
+
+	plan = newTestPlan()
+	planDropColumn(plan, "product", "oldPrice", "drop column old_price", 3, false)
+	assert.True(t, plan.Blocked)
+	assert.Equal(t, []string{"dropping column product.old_price would lose 3 values, reload with force=true to drop it"}, plan.Errors)
+	assert.Equal(t, shared_dto.RiskHigh, plan.Risk)
+
+	plan = newTestPlan()
+	planDropColumn(plan, "product", "oldPrice", "drop column old_price", 3, true)
+	assert.False(t, plan.Blocked)
+	assert.Equal(t, int64(3), plan.Statements[0].AffectedRows)
+}
+
+func TestPlanRename(t *testing.T) {
+	plan := newTestPlan()
+	liveColumns := map[string]string{"id": "text", "name": "text", "price": "real"}
+	planRename(plan, "product", &node_type_model.PropertyType{PID: "name"}, &node_type_model.PropertyType{PID: "productTitle"}, liveColumns)
+
+	assert.False(t, plan.Blocked)
+	assert.Equal(t, []string{
+		`ALTER TABLE "product" RENAME COLUMN "name" TO "product_title";`,
+		"UPDATE property_types SET reference_value = 'productTitle' WHERE reference_type = 'product' AND reference_value = 'name' AND deleted_at IS NULL;",
+	}, statementSQL(plan))
+	assert.Equal(t, map[string]string{"id": "text", "product_title": "text", "price": "real"}, liveColumns)
+	assert.Equal(t, []shared_dto.SchemaRenameDTO{{From: "name", To: "productTitle"}}, plan.renames)
+}
+
+func TestPlanRename_OntoExistingColumn(t *testing.T) {
+	plan := newTestPlan()
+	liveColumns := map[string]string{"name": "text", "title": "text"}
+	planRename(plan, "product", &node_type_model.PropertyType{PID: "name"}, &node_type_model.PropertyType{PID: "title"}, liveColumns)
+
+	assert.True(t, plan.Blocked)
+	assert.Equal(t, []string{"cannot rename column name to title, the column already exists"}, plan.Errors)
+	assert.Empty(t, plan.Statements)
+	assert.Equal(t, map[string]string{"name": "text", "title": "text"}, liveColumns)
+}
+
+func TestPlanRename_MissingColumn(t *testing.T) {
+	plan := newTestPlan()
+	planRename(plan, "product", &node_type_model.PropertyType{PID: "name"}, &node_type_model.PropertyType{PID: "title"}, map[string]string{})
+
+	// Only the references of other node types are rewritten when the column is gone.
+	assert.False(t, plan.Blocked)
+	assert.Equal(t, []string{
+		"UPDATE property_types SET reference_value = 'title' WHERE reference_type = 'product' AND reference_value = 'name' AND deleted_at IS NULL;",
+	}, statementSQL(plan))
+}
+
+func statementSQL(plan *schemaPlan) []string {
+	statements := make([]string, 0, len(plan.Statements))
+	for _, statement := range plan.Statements {
+		statements = append(statements, statement.SQL)
+	}
+	return statements
+}
+
+func TestPlanColumn_RichText(t *testing.T) {
+	s := &HelperService{}
+	plan := newTestPlan()
+	current := &node_type_model.PropertyType{PID: "description", ValueType: "STRING"}
+	pt := &node_type_model.PropertyType{PID: "description", ValueType: "RICH_TEXT"}
+	assert.NoError(t, s.planColumn(plan, "product", current, pt, map[string]string{"description": "text"}, false))
+	assert.Equal(t, []string{"description"}, plan.richTexts)
+	assert.Equal(t, []string{
+		`ALTER TABLE "product" ALTER COLUMN "description" TYPE jsonb USING jsonb_build_object('format', 'html', 'source', "description");`,
+	}, statementSQL(plan))
+
+	plan = newTestPlan()
+	assert.NoError(t, s.planColumn(plan, "product", pt, current, map[string]string{"description": "jsonb"}, false))
+	assert.Empty(t, plan.richTexts)
+	assert.Equal(t, []string{
+		`ALTER TABLE "product" ALTER COLUMN "description" TYPE text USING CASE WHEN jsonb_typeof("description") = 'object' THEN "description" ->> 'source' ELSE "description" #>> '{}' END;`,
+	}, statementSQL(plan))
+}
+
+func TestPlanColumn_JSON(t *testing.T) {
+	s := &HelperService{}
+	plan := newTestPlan()
+	liveColumns := map[string]string{"seo": "text", "meta": "text"}
+	for _, pid := range []string{"seo", "meta"} {
+		current := &node_type_model.PropertyType{PID: pid, ValueType: "STRING"}
+		pt := &node_type_model.PropertyType{PID: pid, ValueType: "JSON"}
+		assert.NoError(t, s.planColumn(plan, "product", current, pt, liveColumns, false))
+	}
+	assert.False(t, plan.Blocked)
+	assert.Equal(t, []string{
+		sql_helper.QueryCreateTryJSONB(),
+		`ALTER TABLE "product" ALTER COLUMN "seo" TYPE jsonb USING try_jsonb("seo");`,
+		`ALTER TABLE "product" ALTER COLUMN "meta" TYPE jsonb USING try_jsonb("meta");`,
+	}, statementSQL(plan))
+}
+
+func TestSlugUpdates(t *testing.T) {
+	text := func(value string) *string { return &value }
+	rows := []slugRow{
+		{ID: "r1", Slug: text("a"), Source: text("A")},
+		{ID: "r2", Slug: text("a")},
+		{ID: "r3", Slug: text("a-2")},
+		{ID: "r4", Slug: text("Áo Thun Đỏ")},
+		{ID: "r5", Source: text("Áo thun đỏ!")},
+		{ID: "r6", Slug: text("!!!")},
+		{ID: "r7"},
+	}
+	assert.Equal(t, map[string]string{"r2": "a-3", "r4": "ao-thun-do", "r5": "ao-thun-do-2", "r6": ""}, slugUpdates(rows))
+}
@@ -0,0 +1,203 @@
+package helper_service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/iancoleman/strcase"
+	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"gorm.io/gorm"
+)
+
+// recordVersion stores the definition applied by plan as the next version of its node type.
+// Loads that change neither the table nor the definition do not create a new version.
+func recordVersion(tx *gorm.DB, plan *schemaPlan, note string) error {
+	var latest node_type_model.NodeTypeVersion
+	err := tx.Where("tid = ?", plan.TID).Order("version DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	definition := plan.nodeType.NodeTypeDTO()
+	if latest.Version > 0 && len(plan.Statements) == 0 && len(plan.renames) == 0 && reflect.DeepEqual(latest.Definition, definition) {
+		return nil
+	}
+
+	statements := make([]string, 0, len(plan.Statements))
+	for _, statement := range plan.Statements {
+		statements = append(statements, statement.SQL)
+	}
+	return tx.Create(&node_type_model.NodeTypeVersion{
+		TID:        plan.TID,
+		Version:    latest.Version + 1,
+		Note:       note,
+		Definition: definition,
+		Statements: statements,
+		Renames:    plan.renames,
+	}).Error
+}
+
+func (s *HelperService) fetchVersion(tid string, version int) (*node_type_model.NodeTypeVersion, error) {
+	var result node_type_model.NodeTypeVersion
+	if err := s.db.Where("tid = ? AND version = ?", tid, version).First(&result).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s v%d", shared_dto.ErrVersionNotFound, tid, version)
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *HelperService) FetchVersions(tid string) ([]shared_dto.NodeTypeVersionDTO, error) {
+	var versions []node_type_model.NodeTypeVersion
+	if err := s.db.Where("tid = ?", strcase.ToLowerCamel(tid)).Order("version DESC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	result := make([]shared_dto.NodeTypeVersionDTO, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, v.NodeTypeVersionDTO())
+	}
+	return result, nil
+}
+
+// DiffVersions compares the property types of two versions and collects the DDL executed in between.
+func (s *HelperService) DiffVersions(tid string, from, to int) (*shared_dto.SchemaVersionDiffDTO, error) {
+	tid = strcase.ToLowerCamel(tid)
+	fromVersion, err := s.fetchVersion(tid, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.fetchVersion(tid, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &shared_dto.SchemaVersionDiffDTO{
+		TID:        tid,
+		From:       from,
+		To:         to,
+		Added:      []shared_dto.PropertyTypeDTO{},
+		Removed:    []shared_dto.PropertyTypeDTO{},
+		Changed:    []shared_dto.PropertyTypeChangeDTO{},
+		Statements: []string{},
+	}
+
+	before := make(map[string]shared_dto.PropertyTypeDTO)
+	for _, pt := range fromVersion.Definition.PropertyTypes {
+		before[pt.PID] = pt
+	}
+	after := make(map[string]bool)
+	for _, pt := range toVersion.Definition.PropertyTypes {
+		after[pt.PID] = true
+		previous, exists := before[pt.PID]
+		if !exists {
+			diff.Added = append(diff.Added, pt)
+			continue
+		}
+		if !reflect.DeepEqual(previous, pt) {
+			diff.Changed = append(diff.Changed, shared_dto.PropertyTypeChangeDTO{PID: pt.PID, Before: previous, After: pt})
+		}
+	}
+	for _, pt := range fromVersion.Definition.PropertyTypes {
+		if !after[pt.PID] {
+			diff.Removed = append(diff.Removed, pt)
+		}
+	}
+
+	if from < to {
+		var between []node_type_model.NodeTypeVersion
+		if err := s.db.Where("tid = ? AND version > ? AND version <= ?", tid, from, to).Order("version").Find(&between).Error; err != nil {
+			return nil, err
+		}
+		for _, v := range between {
+			diff.Statements = append(diff.Statements, v.Statements...)
+		}
+	}
+	return diff, nil
+}
+
+// Rollback re-applies the definition of a previous version through the regular migration path,
+// which records it as a new version. The renames of the later versions are reverted.
+func (s *HelperService) Rollback(tid string, version int, force bool) (*shared_dto.SchemaPlanDTO, error) {
+	tid = strcase.ToLowerCamel(tid)
+	target, err := s.fetchVersion(tid, version)
+	if err != nil {
+		return nil, err
+	}
+	nodeType, err := node_type_model.NodeTypeFromDTO(target.Definition)
+	if err != nil {
+		return nil, err
+	}
+	var later []node_type_model.NodeTypeVersion
+	if err := s.db.Where("tid = ? AND version > ?", tid, version).Order("version").Find(&later).Error; err != nil {
+		return nil, err
+	}
+	revertRenames(nodeType, later)
+
+	plan, err := s.planNodeType(nodeType, force)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyPlan(plan, fmt.Sprintf("rollback to version %d", version)); err != nil {
+		return &plan.SchemaPlanDTO, err
+	}
+	return &plan.SchemaPlanDTO, nil
+}
+
+// revertRenames declares on nodeType, the definition of an earlier version, the renames applied by the later
+// versions as renamedFrom, so that the columns and the options are renamed back rather than dropped and added.
+// Versions recorded before renames were stored have none; the data-loss check then blocks the rollback.
+func revertRenames(nodeType *node_type_model.NodeType, later []node_type_model.NodeTypeVersion) {
+	// pids maps the pids of nodeType to their current name, options do the same for the option values.
+	pids := make(map[string]string, len(nodeType.PropertyTypes))
+	options := make(map[string]map[string]string, len(nodeType.PropertyTypes))
+	for _, pt := range nodeType.PropertyTypes {
+		pids[pt.PID] = pt.PID
+		options[pt.PID] = make(map[string]string, len(pt.Options))
+		for _, option := range pt.Options {
+			options[pt.PID][option.Value] = option.Value
+		}
+	}
+	origin := func(names map[string]string, current string) (string, bool) {
+		for name, now := range names {
+			if now == current {
+				return name, true
+			}
+		}
+		return "", false
+	}
+
+	for _, v := range later {
+		// Properties are renamed before their options, which carry the new pid.
+		for _, rename := range v.Renames {
+			if pid, found := origin(pids, rename.From); found && len(rename.PID) == 0 {
+				pids[pid] = rename.To
+			}
+		}
+		for _, rename := range v.Renames {
+			if len(rename.PID) == 0 {
+				continue
+			}
+			if pid, found := origin(pids, rename.PID); found {
+				if value, found := origin(options[pid], rename.From); found {
+					options[pid][value] = rename.To
+				}
+			}
+		}
+	}
+
+	for _, pt := range nodeType.PropertyTypes {
+		if current := pids[pt.PID]; current != pt.PID {
+			pt.RenamedFrom = current
+		}
+		for i, option := range pt.Options {
+			current := options[pt.PID][option.Value]
+			if current != option.Value && !slices.ContainsFunc(pt.Options, func(o shared_dto.EnumOptionDTO) bool { return o.Value == current }) {
+				pt.Options[i].RenamedFrom = current
+			}
+		}
+	}
+}
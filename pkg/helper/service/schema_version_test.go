@@ -0,0 +1,28 @@
+package helper_service
+
+import (
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevertRenames(t *testing.T) {
+	nodeType := &node_type_model.NodeType{TID: "article", PropertyTypes: []*node_type_model.PropertyType{
+		{PID: "name", ValueType: "STRING"},
+		{PID: "state", ValueType: "ENUM", Options: []shared_dto.EnumOptionDTO{{Value: "draft"}, {Value: "published"}}},
+		{PID: "body", ValueType: "STRING"},
+	}}
+	revertRenames(nodeType, []node_type_model.NodeTypeVersion{
+		{Version: 2, Renames: []shared_dto.SchemaRenameDTO{{From: "name", To: "title"}, {From: "state", To: "status"}}},
+		{Version: 3, Renames: []shared_dto.SchemaRenameDTO{{PID: "status", From: "published", To: "live"}}},
+		{Version: 4, Renames: []shared_dto.SchemaRenameDTO{{From: "title", To: "headline"}}},
+	})
+
+	assert.Equal(t, "headline", nodeType.PropertyTypes[0].RenamedFrom)
+	assert.Equal(t, "status", nodeType.PropertyTypes[1].RenamedFrom)
+	assert.Equal(t, []shared_dto.EnumOptionDTO{{Value: "draft"}, {Value: "published", RenamedFrom: "live"}}, nodeType.PropertyTypes[1].Options)
+	assert.Empty(t, nodeType.PropertyTypes[2].RenamedFrom)
+	assert.NoError(t, nodeType.Validate())
+}
@@ -0,0 +1,154 @@
+package sql_helper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+)
+
+const (
+	groupColumnPrefix  = "__group_"
+	labelColumnPrefix  = "__label_"
+	metricColumnPrefix = "__metric_"
+	countColumn        = "__count"
+)
+
+type aggregationGroup struct {
+	key   string
+	field QueryField
+	// label is the referenceValue of the record a REFERENCE group points at.
+	label *QueryField
+}
+
+type aggregationMetric struct {
+	function string
+	key      string
+	field    QueryField
+}
+
+// Aggregation counts the rows of each group of the groupBy fields and computes the aggregate functions
+// of their numeric fields. Without groupBy the whole table is a single group.
+type Aggregation struct {
+	groups  []aggregationGroup
+	metrics []aggregationMetric
+}
+
+// NewAggregation resolves the groupBy fields and the numeric fields of each function of metrics against schema.
+// A REFERENCE groupBy field is labelled with the referenceValue property of the referenced record.
+func NewAggregation(schema *QuerySchema, groupBy []string, metrics map[string][]string) (*Aggregation, error) {
+	a := &Aggregation{}
+	for _, key := range groupBy {
+		field, err := schema.Resolve(key)
+		if err != nil {
+			return nil, err
+		}
+		if field.IsMultiValued() {
+			return nil, fmt.Errorf("%w: cannot group by %s, it holds several values", shared_dto.ErrInvalidQuery, key)
+		}
+		group := aggregationGroup{key: key, field: field}
+		if field.ValueType() == value_type.Reference && len(field.PropertyType.ReferenceValue) > 0 {
+			if label, err := schema.Resolve(key + "." + field.PropertyType.ReferenceValue); err == nil {
+				group.label = &label
+			}
+		}
+		a.groups = append(a.groups, group)
+	}
+
+	for _, function := range shared_utils.AggregateFunctions {
+		for _, key := range metrics[function] {
+			field, err := schema.Resolve(key)
+			if err != nil {
+				return nil, err
+			}
+			if !field.ValueType().Numeric() {
+				return nil, fmt.Errorf("%w: cannot compute %s of %s, it is not numeric", shared_dto.ErrInvalidQuery, function, key)
+			}
+			a.metrics = append(a.metrics, aggregationMetric{function: function, key: key, field: field})
+		}
+	}
+	return a, nil
+}
+
+// Select returns the group columns, the count and the aggregate of every metric.
+func (a *Aggregation) Select() string {
+	columns := make([]string, 0, len(a.groups)*2+len(a.metrics)+1)
+	for i, group := range a.groups {
+		columns = append(columns, fmt.Sprintf("%s AS %s", group.field.SQL(), QuoteIdentifier(groupColumnPrefix+strconv.Itoa(i))))
+		if group.label != nil {
+			columns = append(columns, fmt.Sprintf("%s AS %s", group.label.SQL(), QuoteIdentifier(labelColumnPrefix+strconv.Itoa(i))))
+		}
+	}
+	columns = append(columns, "COUNT(*) AS "+QuoteIdentifier(countColumn))
+	for i, metric := range a.metrics {
+		columns = append(columns, fmt.Sprintf("%s AS %s", metric.SQL(), QuoteIdentifier(metricColumnPrefix+strconv.Itoa(i))))
+	}
+	return strings.Join(columns, ", ")
+}
+
+// SQL returns the aggregate, sums and averages are cast so that they are not scanned as numeric strings.
+func (m aggregationMetric) SQL() string {
+	switch {
+	case m.function == "avg":
+		return fmt.Sprintf("AVG(%s)::double precision", m.field.SQL())
+	case m.function == "sum" && m.field.ValueType() == value_type.Integer:
+		return fmt.Sprintf("SUM(%s)::bigint", m.field.SQL())
+	case m.function == "sum":
+		return fmt.Sprintf("SUM(%s)::double precision", m.field.SQL())
+	default:
+		return fmt.Sprintf("%s(%s)", strings.ToUpper(m.function), m.field.SQL())
+	}
+}
+
+// GroupBy returns the GROUP BY clause, empty without groupBy fields.
+func (a *Aggregation) GroupBy() string {
+	columns := make([]string, 0, len(a.groups)*2)
+	for _, group := range a.groups {
+		columns = append(columns, group.field.SQL())
+		if group.label != nil {
+			columns = append(columns, group.label.SQL())
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// OrderBy puts the largest groups first.
+func (a *Aggregation) OrderBy() string {
+	clauses := []string{QuoteIdentifier(countColumn) + " DESC"}
+	for _, group := range a.groups {
+		clauses = append(clauses, group.field.SQL()+" ASC NULLS LAST")
+	}
+	return strings.Join(clauses, ", ")
+}
+
+// Format turns the rows into `{ "group": {...}, "count": n, "<function>": { "<field>": value } }`.
+func (a *Aggregation) Format(rows []map[string]interface{}) []map[string]interface{} {
+	results := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		result := map[string]interface{}{"count": row[countColumn]}
+		if len(a.groups) > 0 {
+			group := make(map[string]interface{}, len(a.groups))
+			for i, g := range a.groups {
+				value := row[groupColumnPrefix+strconv.Itoa(i)]
+				if g.label != nil && value != nil {
+					value = map[string]interface{}{"id": value, "label": row[labelColumnPrefix+strconv.Itoa(i)]}
+				}
+				group[g.key] = value
+			}
+			result["group"] = group
+		}
+		for i, metric := range a.metrics {
+			values, ok := result[metric.function].(map[string]interface{})
+			if !ok {
+				values = make(map[string]interface{})
+				result[metric.function] = values
+			}
+			values[metric.key] = row[metricColumnPrefix+strconv.Itoa(i)]
+		}
+		results = append(results, result)
+	}
+	return results
+}
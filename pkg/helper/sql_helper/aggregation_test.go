@@ -0,0 +1,83 @@
+package sql_helper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+var aggregationPropertyTypes = map[string][]shared_dto.PropertyTypeDTO{
+	"product": {
+		{PID: "name", ValueType: "STRING"},
+		{PID: "price", ValueType: "DOUBLE"},
+		{PID: "stock", ValueType: "INT"},
+		{PID: "category", ValueType: "REFERENCE", ReferenceType: "category", ReferenceValue: "title"},
+		{PID: "tags", ValueType: "REFERENCES", ReferenceType: "tag"},
+	},
+	"category": {{PID: "title", ValueType: "STRING"}},
+}
+
+func aggregationSchema() *QuerySchema {
+	schema := NewQuerySchema("product", aggregationPropertyTypes["product"])
+	schema.AutoJoin(func(tid string) []shared_dto.PropertyTypeDTO { return aggregationPropertyTypes[tid] }, 2)
+	return schema
+}
+
+func TestAggregation(t *testing.T) {
+	schema := aggregationSchema()
+	aggregation, err := NewAggregation(schema, []string{"category", "name"}, map[string][]string{"sum": {"stock", "price"}, "avg": {"price"}})
+	assert.NoError(t, err)
+
+	assert.Equal(t, `"product"."category" AS "__group_0", "category"."title" AS "__label_0", "product"."name" AS "__group_1", `+
+		`COUNT(*) AS "__count", SUM("product"."stock")::bigint AS "__metric_0", SUM("product"."price")::double precision AS "__metric_1", `+
+		`AVG("product"."price")::double precision AS "__metric_2"`, aggregation.Select())
+	assert.Equal(t, `"product"."category", "category"."title", "product"."name"`, aggregation.GroupBy())
+	assert.Equal(t, `"__count" DESC, "product"."category" ASC NULLS LAST, "product"."name" ASC NULLS LAST`, aggregation.OrderBy())
+	assert.Equal(t, ` LEFT JOIN "category" AS "category" ON "category".id = "product"."category" AND "category".deleted_at IS NULL`, schema.JoinSQL())
+
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"group": map[string]interface{}{"category": map[string]interface{}{"id": "c1", "label": "Shoes"}, "name": "boot"},
+			"count": int64(2),
+			"sum":   map[string]interface{}{"stock": int64(7), "price": 30.5},
+			"avg":   map[string]interface{}{"price": 15.25},
+		},
+		{
+			"group": map[string]interface{}{"category": nil, "name": nil},
+			"count": int64(1),
+			"sum":   map[string]interface{}{"stock": nil, "price": nil},
+			"avg":   map[string]interface{}{"price": nil},
+		},
+	}, aggregation.Format([]map[string]interface{}{
+		{"__group_0": "c1", "__label_0": "Shoes", "__group_1": "boot", "__count": int64(2), "__metric_0": int64(7), "__metric_1": 30.5, "__metric_2": 15.25},
+		{"__group_0": nil, "__label_0": nil, "__group_1": nil, "__count": int64(1), "__metric_0": nil, "__metric_1": nil, "__metric_2": nil},
+	}))
+}
+
+func TestAggregation_Totals(t *testing.T) {
+	aggregation, err := NewAggregation(aggregationSchema(), nil, map[string][]string{"max": {"price"}})
+	assert.NoError(t, err)
+
+	assert.Equal(t, `COUNT(*) AS "__count", MAX("product"."price") AS "__metric_0"`, aggregation.Select())
+	assert.Empty(t, aggregation.GroupBy())
+	assert.Equal(t, []map[string]interface{}{{"count": int64(3), "max": map[string]interface{}{"price": 9.5}}},
+		aggregation.Format([]map[string]interface{}{{"__count": int64(3), "__metric_0": 9.5}}))
+}
+
+func TestAggregation_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		groupBy []string
+		metrics map[string][]string
+	}{
+		{groupBy: []string{"tags"}},
+		{groupBy: []string{"missing"}},
+		{metrics: map[string][]string{"sum": {"name"}}},
+		{metrics: map[string][]string{"avg": {"category"}}},
+		{metrics: map[string][]string{"min": {"createdAt"}}},
+	} {
+		_, err := NewAggregation(aggregationSchema(), tc.groupBy, tc.metrics)
+		assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery), tc)
+	}
+}
@@ -0,0 +1,211 @@
+package sql_helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+)
+
+// expansionColumnPrefix names the columns holding expanded references until FormatExpansions
+// puts them in place of the reference ids.
+const expansionColumnPrefix = "__ref_"
+
+// Expansion is a REFERENCE or REFERENCES property whose ids are replaced by the referenced records.
+type Expansion struct {
+	PID      string
+	Column   string
+	Table    string
+	Many     bool
+	Fields   []string
+	Children []*Expansion
+
+	propertyTypes []shared_dto.PropertyTypeDTO
+}
+
+// PropertyTypeLookup returns the property types of a node type.
+type PropertyTypeLookup func(tid string) []shared_dto.PropertyTypeDTO
+
+// BuildExpansions resolves `reference.nested.deeper` paths into a tree of expansions,
+// rejecting paths that are not references or go deeper than maxDepth.
+func BuildExpansions(propertyTypes []shared_dto.PropertyTypeDTO, paths []string, maxDepth int, lookup PropertyTypeLookup) ([]*Expansion, error) {
+	var roots []*Expansion
+	for _, path := range paths {
+		segments := strings.Split(path, ".")
+		if len(segments) > maxDepth {
+			return nil, fmt.Errorf("%w: %s expands %d levels, at most %d are allowed", shared_dto.ErrInvalidQuery, path, len(segments), maxDepth)
+		}
+
+		level, levelPropertyTypes := &roots, propertyTypes
+		for _, pid := range segments {
+			expansion := findExpansion(*level, pid)
+			if expansion == nil {
+				pt := findPropertyType(levelPropertyTypes, pid)
+				if pt == nil {
+					return nil, fmt.Errorf("%w: %s in %s is not a property", shared_dto.ErrInvalidQuery, pid, path)
+				}
+				vt := value_type.ValueType(pt.ValueType)
+				if (vt != value_type.Reference && vt != value_type.References) || len(pt.ReferenceType) == 0 {
+					return nil, fmt.Errorf("%w: %s in %s is not a reference", shared_dto.ErrInvalidQuery, pid, path)
+				}
+				expansion = &Expansion{
+					PID:           pt.PID,
+					Column:        strcase.ToSnake(pt.PID),
+					Table:         strcase.ToSnake(pt.ReferenceType),
+					Many:          vt == value_type.References,
+					propertyTypes: lookup(pt.ReferenceType),
+				}
+				*level = append(*level, expansion)
+			}
+			level, levelPropertyTypes = &expansion.Children, expansion.propertyTypes
+		}
+	}
+	return roots, nil
+}
+
+// FindExpansion follows a `reference.nested` path through the expansion tree.
+func FindExpansion(expansions []*Expansion, path string) *Expansion {
+	var expansion *Expansion
+	for _, pid := range strings.Split(path, ".") {
+		if expansion = findExpansion(expansions, pid); expansion == nil {
+			return nil
+		}
+		expansions = expansion.Children
+	}
+	return expansion
+}
+
+func findExpansion(expansions []*Expansion, pid string) *Expansion {
+	for _, e := range expansions {
+		if e.PID == pid || e.Column == strcase.ToSnake(pid) {
+			return e
+		}
+	}
+	return nil
+}
+
+func findPropertyType(propertyTypes []shared_dto.PropertyTypeDTO, pid string) *shared_dto.PropertyTypeDTO {
+	for i := range propertyTypes {
+		if propertyTypes[i].PID == pid || strcase.ToSnake(propertyTypes[i].PID) == strcase.ToSnake(pid) {
+			return &propertyTypes[i]
+		}
+	}
+	return nil
+}
+
+// Project limits the columns of the expanded records to fields, the id is always kept.
+func (e *Expansion) Project(fields ...string) error {
+	for _, name := range fields {
+		column := strcase.ToSnake(name)
+		if _, system := systemColumns[column]; !system && findPropertyType(e.propertyTypes, name) == nil {
+			return fmt.Errorf("%w: unknown field %s of %s", shared_dto.ErrInvalidQuery, name, e.PID)
+		}
+		if len(e.Fields) == 0 && column != "id" {
+			e.Fields = append(e.Fields, "id")
+		}
+		if !containsString(e.Fields, column) {
+			e.Fields = append(e.Fields, column)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpansionSelect returns the correlated subqueries selecting each expansion of the rows of parentTable.
+func ExpansionSelect(parentTable string, expansions []*Expansion) string {
+	counter := 0
+	columns := make([]string, 0, len(expansions))
+	for _, e := range expansions {
+		columns = append(columns, fmt.Sprintf("%s AS %s", e.subquery(QuoteIdentifier(parentTable), &counter), QuoteIdentifier(expansionColumnPrefix+e.PID)))
+	}
+	return strings.Join(columns, ", ")
+}
+
+// subquery builds the jsonb of the referenced record, or the jsonb array of the referenced records,
+// with the nested expansions merged in and without the search column.
+func (e *Expansion) subquery(parent string, counter *int) string {
+	*counter++
+	alias := QuoteIdentifier(fmt.Sprintf("ref_%d", *counter))
+
+	row := fmt.Sprintf("to_jsonb(%s.*) - %s", alias, QuoteLiteral(SearchColumn))
+	if len(e.Fields) > 0 {
+		pairs := make([]string, 0, len(e.Fields))
+		for _, field := range e.Fields {
+			pairs = append(pairs, fmt.Sprintf("%s, %s.%s", QuoteLiteral(field), alias, QuoteIdentifier(field)))
+		}
+		row = fmt.Sprintf("jsonb_build_object(%s)", strings.Join(pairs, ", "))
+	}
+	if len(e.Children) > 0 {
+		pairs := make([]string, 0, len(e.Children))
+		for _, child := range e.Children {
+			pairs = append(pairs, fmt.Sprintf("%s, %s", QuoteLiteral(child.Column), child.subquery(alias, counter)))
+		}
+		row = fmt.Sprintf("%s || jsonb_build_object(%s)", row, strings.Join(pairs, ", "))
+	}
+
+	column := parent + "." + QuoteIdentifier(e.Column)
+	if !e.Many {
+		return fmt.Sprintf("(SELECT %s FROM %s AS %s WHERE %s.id = %s AND %s.deleted_at IS NULL)",
+			row, QuoteIdentifier(e.Table), alias, alias, column, alias)
+	}
+
+	return fmt.Sprintf("(SELECT COALESCE(jsonb_agg(%s ORDER BY array_position(%s, %s.id)), '[]'::jsonb) FROM %s AS %s WHERE %s.id = ANY(%s) AND %s.deleted_at IS NULL)",
+		row, column, alias, QuoteIdentifier(e.Table), alias, alias, column, alias)
+}
+
+// FormatExpansions replaces the reference ids of the records with the expanded records, whose RICH_TEXT
+// properties are returned in richTextFormat.
+func FormatExpansions(records []map[string]interface{}, expansions []*Expansion, richTextFormat string) {
+	for _, record := range records {
+		for _, e := range expansions {
+			key := expansionColumnPrefix + e.PID
+			value, exists := record[key]
+			if !exists {
+				continue
+			}
+			delete(record, key)
+
+			if s, isString := value.(string); isString && shared_utils.IsJSON(s) {
+				var expanded interface{}
+				if err := json.Unmarshal([]byte(s), &expanded); err == nil {
+					value = expanded
+				}
+			}
+			e.format(value, richTextFormat)
+			record[e.Column] = value
+		}
+	}
+}
+
+// format converts the RICH_TEXT properties of the expanded records and of their own expansions into
+// richTextFormat. to_jsonb already writes the values of the other properties as JSON.
+func (e *Expansion) format(value interface{}, richTextFormat string) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			e.format(item, richTextFormat)
+		}
+	case map[string]interface{}:
+		for _, pt := range e.propertyTypes {
+			column := strcase.ToSnake(pt.PID)
+			if field, exists := v[column]; exists && value_type.ValueType(pt.ValueType) == value_type.RichText {
+				v[column] = value_type.FormatRichText(field, richTextFormat)
+			}
+		}
+		for _, child := range e.Children {
+			child.format(v[child.Column], richTextFormat)
+		}
+	}
+}
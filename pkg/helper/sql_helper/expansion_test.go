@@ -0,0 +1,101 @@
+package sql_helper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+var expansionPropertyTypes = map[string][]shared_dto.PropertyTypeDTO{
+	"product": {
+		{PID: "name", ValueType: "STRING"},
+		{PID: "brand", ValueType: "REFERENCE", ReferenceType: "brand"},
+		{PID: "tags", ValueType: "REFERENCES", ReferenceType: "tag"},
+	},
+	"brand": {
+		{PID: "title", ValueType: "STRING"},
+		{PID: "parentBrand", ValueType: "REFERENCE", ReferenceType: "brand"},
+		{PID: "story", ValueType: "RICH_TEXT"},
+	},
+	"tag": {
+		{PID: "label", ValueType: "STRING"},
+	},
+}
+
+func productExpansions(t *testing.T, paths ...string) []*Expansion {
+	expansions, err := BuildExpansions(expansionPropertyTypes["product"], paths, 3, func(tid string) []shared_dto.PropertyTypeDTO {
+		return expansionPropertyTypes[tid]
+	})
+	assert.NoError(t, err)
+	return expansions
+}
+
+func TestBuildExpansions(t *testing.T) {
+	expansions := productExpansions(t, "brand.parentBrand", "tags", "brand")
+	assert.Len(t, expansions, 2)
+	assert.Equal(t, "brand", expansions[0].Table)
+	assert.False(t, expansions[0].Many)
+	assert.Equal(t, "parent_brand", expansions[0].Children[0].Column)
+	assert.True(t, expansions[1].Many)
+	assert.Same(t, expansions[0].Children[0], FindExpansion(expansions, "brand.parentBrand"))
+	assert.Nil(t, FindExpansion(expansions, "tags.label"))
+
+	lookup := func(tid string) []shared_dto.PropertyTypeDTO { return expansionPropertyTypes[tid] }
+	for _, path := range []string{"name", "missing", "brand.title", "brand.parentBrand.parentBrand.parentBrand"} {
+		_, err := BuildExpansions(expansionPropertyTypes["product"], []string{path}, 3, lookup)
+		assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery), path)
+	}
+}
+
+func TestExpansionSelect(t *testing.T) {
+	expansions := productExpansions(t, "brand.parentBrand", "tags")
+	assert.NoError(t, expansions[1].Project("label"))
+
+	assert.Equal(t,
+		`(SELECT to_jsonb("ref_1".*) - 'search_vector' || jsonb_build_object('parent_brand', `+
+			`(SELECT to_jsonb("ref_2".*) - 'search_vector' FROM "brand" AS "ref_2" WHERE "ref_2".id = "ref_1"."parent_brand" AND "ref_2".deleted_at IS NULL)) `+
+			`FROM "brand" AS "ref_1" WHERE "ref_1".id = "product"."brand" AND "ref_1".deleted_at IS NULL) AS "__ref_brand", `+
+			`(SELECT COALESCE(jsonb_agg(jsonb_build_object('id', "ref_3"."id", 'label', "ref_3"."label") `+
+			`ORDER BY array_position("product"."tags", "ref_3".id)), '[]'::jsonb) `+
+			`FROM "tag" AS "ref_3" WHERE "ref_3".id = ANY("product"."tags") AND "ref_3".deleted_at IS NULL) AS "__ref_tags"`,
+		ExpansionSelect("product", expansions))
+}
+
+func TestExpansionSelect_OmitsSearchColumn(t *testing.T) {
+	expansions := productExpansions(t, "brand", "tags")
+	assert.Equal(t,
+		`(SELECT to_jsonb("ref_1".*) - 'search_vector' FROM "brand" AS "ref_1" `+
+			`WHERE "ref_1".id = "product"."brand" AND "ref_1".deleted_at IS NULL) AS "__ref_brand", `+
+			`(SELECT COALESCE(jsonb_agg(to_jsonb("ref_2".*) - 'search_vector' ORDER BY array_position("product"."tags", "ref_2".id)), '[]'::jsonb) `+
+			`FROM "tag" AS "ref_2" WHERE "ref_2".id = ANY("product"."tags") AND "ref_2".deleted_at IS NULL) AS "__ref_tags"`,
+		ExpansionSelect("product", expansions))
+}
+
+func TestFormatExpansions(t *testing.T) {
+	records := []map[string]interface{}{
+		{"id": "p1", "brand": "b1", "__ref_brand": `{"id":"b1","title":"Acme"}`, "tags": "t1", "__ref_tags": `[{"id":"t1"}]`},
+	}
+	FormatExpansions(records, productExpansions(t, "brand", "tags"), "html")
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"id":    "p1",
+			"brand": map[string]interface{}{"id": "b1", "title": "Acme"},
+			"tags":  []interface{}{map[string]interface{}{"id": "t1"}},
+		},
+	}, records)
+}
+
+func TestFormatExpansions_RichText(t *testing.T) {
+	story := `{"format":"markdown","source":"**Bold**","html":"<p><strong>Bold</strong></p>","text":"Bold"}`
+	records := []map[string]interface{}{
+		{"id": "p1", "brand": "b1", "__ref_brand": `{"id":"b1","story":` + story + `,"parent_brand":{"id":"b0","story":` + story + `}}`},
+	}
+	FormatExpansions(records, productExpansions(t, "brand.parentBrand"), "markdown")
+	assert.Equal(t, map[string]interface{}{
+		"id":           "b1",
+		"story":        "**Bold**",
+		"parent_brand": map[string]interface{}{"id": "b0", "story": "**Bold**"},
+	}, records[0]["brand"])
+}
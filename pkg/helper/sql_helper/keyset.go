@@ -0,0 +1,157 @@
+package sql_helper
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+)
+
+// cursorColumnPrefix names the extra columns selecting the sort values of each row, they are removed
+// from the records with StripCursorColumns.
+const cursorColumnPrefix = "__cursor_"
+
+// Keyset pages through records ordered by the sort keys followed by id, which makes the order total.
+type Keyset struct {
+	keys []SortField
+}
+
+type keysetCursor struct {
+	Signature string        `json:"s"`
+	Values    []interface{} `json:"v"`
+}
+
+func NewKeyset(schema *QuerySchema, sorts []SortField) Keyset {
+	keys := append([]SortField{}, sorts...)
+	id, _ := schema.Resolve("id")
+	for _, sf := range keys {
+		if sf.Field == id {
+			return Keyset{keys: keys}
+		}
+	}
+	return Keyset{keys: append(keys, SortField{Field: id})}
+}
+
+func (k Keyset) OrderBy() string {
+	return compileOrderBy(k.keys)
+}
+
+// SelectColumns selects the value of every sort key under a hidden column name.
+func (k Keyset) SelectColumns() string {
+	columns := make([]string, 0, len(k.keys))
+	for i, sf := range k.keys {
+		columns = append(columns, fmt.Sprintf("%s AS %s", sf.Field.SQL(), QuoteIdentifier(cursorColumnPrefix+strconv.Itoa(i))))
+	}
+	return strings.Join(columns, ", ")
+}
+
+// signature identifies the sort order, a cursor is only valid for the order it was created with.
+func (k Keyset) signature() string {
+	h := fnv.New32a()
+	h.Write([]byte(k.OrderBy()))
+	return strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+// NextCursor encodes the sort values of the last record of a page.
+func (k Keyset) NextCursor(record map[string]interface{}) (string, error) {
+	values := make([]interface{}, len(k.keys))
+	for i := range k.keys {
+		value, ok := record[cursorColumnPrefix+strconv.Itoa(i)]
+		if !ok {
+			return "", fmt.Errorf("record has no sort value %d", i)
+		}
+		if t, isTime := value.(time.Time); isTime {
+			value = t.Format(time.RFC3339Nano)
+		}
+		values[i] = value
+	}
+	data, err := json.Marshal(keysetCursor{Signature: k.signature(), Values: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Condition returns the condition selecting the records after the cursor. Rows without a value sort last,
+// so a row is after the cursor when it equals it on the first keys and comes later on the next one.
+func (k Keyset) Condition(cursor string) (string, []interface{}, error) {
+	values, err := k.decode(cursor)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var alternatives []string
+	var params []interface{}
+	for i, sf := range k.keys {
+		if values[i] == nil {
+			// Nothing sorts after NULL on this key.
+			continue
+		}
+		var parts []string
+		var partParams []interface{}
+		for j := 0; j < i; j++ {
+			column := k.keys[j].Field.SQL()
+			if values[j] == nil {
+				parts = append(parts, column+" IS NULL")
+				continue
+			}
+			parts = append(parts, column+" = ?")
+			partParams = append(partParams, values[j])
+		}
+		op := ">"
+		if sf.Desc {
+			op = "<"
+		}
+		column := sf.Field.SQL()
+		parts = append(parts, fmt.Sprintf("(%s %s ? OR %s IS NULL)", column, op, column))
+		partParams = append(partParams, values[i])
+
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+		params = append(params, partParams...)
+	}
+	if len(alternatives) == 0 {
+		return "1 = 0", nil, nil
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", params, nil
+}
+
+func (k Keyset) decode(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", shared_dto.ErrInvalidQuery)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var c keysetCursor
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", shared_dto.ErrInvalidQuery)
+	}
+	if c.Signature != k.signature() || len(c.Values) != len(k.keys) {
+		return nil, fmt.Errorf("%w: cursor does not match the sort order", shared_dto.ErrInvalidQuery)
+	}
+	for i, value := range c.Values {
+		if number, ok := value.(json.Number); ok {
+			if n, err := number.Int64(); err == nil {
+				c.Values[i] = n
+			} else if f, err := number.Float64(); err == nil {
+				c.Values[i] = f
+			}
+		}
+	}
+	return c.Values, nil
+}
+
+// StripCursorColumns removes the hidden sort value columns from a record.
+func StripCursorColumns(record map[string]interface{}) {
+	for key := range record {
+		if strings.HasPrefix(key, cursorColumnPrefix) {
+			delete(record, key)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package sql_helper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyset_CursorRoundTrip(t *testing.T) {
+	schema := productQuerySchema()
+	sorts, err := schema.ParseSort("stock desc,createdAt")
+	assert.NoError(t, err)
+	keyset := NewKeyset(schema, sorts)
+
+	assert.Equal(t, `"product"."stock" DESC NULLS LAST, "product"."created_at" ASC NULLS LAST, "product"."id" ASC NULLS LAST`, keyset.OrderBy())
+	assert.Equal(t, `"product"."stock" AS "__cursor_0", "product"."created_at" AS "__cursor_1", "product"."id" AS "__cursor_2"`, keyset.SelectColumns())
+
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC)
+	record := map[string]interface{}{"id": "a1", "__cursor_0": int32(7), "__cursor_1": createdAt, "__cursor_2": "a1"}
+	cursor, err := keyset.NextCursor(record)
+	assert.NoError(t, err)
+
+	condition, values, err := keyset.Condition(cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, `((("product"."stock" < ? OR "product"."stock" IS NULL)) OR `+
+		`("product"."stock" = ? AND ("product"."created_at" > ? OR "product"."created_at" IS NULL)) OR `+
+		`("product"."stock" = ? AND "product"."created_at" = ? AND ("product"."id" > ? OR "product"."id" IS NULL)))`, condition)
+	ts := createdAt.Format(time.RFC3339Nano)
+	assert.Equal(t, []interface{}{int64(7), int64(7), ts, int64(7), ts, "a1"}, values)
+
+	StripCursorColumns(record)
+	assert.Equal(t, map[string]interface{}{"id": "a1"}, record)
+}
+
+func TestKeyset_NullSortValue(t *testing.T) {
+	schema := productQuerySchema()
+	sorts, _ := schema.ParseSort("name")
+	keyset := NewKeyset(schema, sorts)
+
+	cursor, err := keyset.NextCursor(map[string]interface{}{"__cursor_0": nil, "__cursor_1": "b2"})
+	assert.NoError(t, err)
+	condition, values, err := keyset.Condition(cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, `(("product"."name" IS NULL AND ("product"."id" > ? OR "product"."id" IS NULL)))`, condition)
+	assert.Equal(t, []interface{}{"b2"}, values)
+}
+
+func TestKeyset_RejectsCursorOfAnotherSort(t *testing.T) {
+	schema := productQuerySchema()
+	byName, _ := schema.ParseSort("name")
+	byStock, _ := schema.ParseSort("stock")
+
+	cursor, err := NewKeyset(schema, byName).NextCursor(map[string]interface{}{"__cursor_0": "x", "__cursor_1": "a"})
+	assert.NoError(t, err)
+
+	_, _, err = NewKeyset(schema, byStock).Condition(cursor)
+	assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery))
+
+	_, _, err = NewKeyset(schema, byName).Condition("not a cursor")
+	assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery))
+}
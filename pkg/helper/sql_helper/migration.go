@@ -0,0 +1,477 @@
+package sql_helper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+)
+
+const (
+	integerPattern = `^[+-]?[0-9]+$`
+	numericPattern = `^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`
+)
+
+// temporalPatterns match the ISO-8601 text a date, timestamptz or time column can be converted from.
+var temporalPatterns = map[string]string{
+	"date":        `^[0-9]{4}-[0-9]{2}-[0-9]{2}$`,
+	"timestamptz": `^[0-9]{4}-[0-9]{2}-[0-9]{2}([T ][0-9]{2}:[0-9]{2}(:[0-9]{2}(\.[0-9]+)?)?(Z|[+-][0-9]{2}(:?[0-9]{2})?)?)?$`,
+	"time":        `^[0-9]{2}:[0-9]{2}(:[0-9]{2}(\.[0-9]+)?)?$`,
+}
+
+// ColumnCast describes how the values of a column are converted when its SQL type changes.
+// Cast and Invalid are templates where %[1]s is replaced by the column name. Setup, when set, is the
+// statement creating the function Cast calls.
+type ColumnCast struct {
+	From    string
+	To      string
+	Cast    string
+	Invalid string
+	Setup   string
+}
+
+// QueryCreateTryJSONB creates try_jsonb(text), which parses JSON text and turns any other text into a JSON
+// string. Unlike `IS JSON`, it does not need PostgreSQL 16.
+func QueryCreateTryJSONB() string {
+	return `CREATE OR REPLACE FUNCTION try_jsonb(value text) RETURNS jsonb LANGUAGE plpgsql IMMUTABLE AS $$
+BEGIN
+	RETURN value::jsonb;
+EXCEPTION WHEN invalid_text_representation THEN
+	RETURN to_jsonb(value);
+END
+$$;`
+}
+
+// Lossless reports whether every value of the column can be converted.
+func (cc ColumnCast) Lossless() bool {
+	return len(cc.Invalid) == 0
+}
+
+// FindColumnCast returns the conversion between two SQL types, or false when no safe conversion exists.
+func FindColumnCast(from, to string) (ColumnCast, bool) {
+	cc := ColumnCast{From: from, To: to}
+	switch {
+	case from == to:
+		cc.Cast = "%[1]s"
+	case from == "text[]" && to == "text":
+		cc.Cast = "array_to_string(%[1]s, ',')"
+	case from == "text" && to == "text[]":
+		// Comma-separated ids, as REFERENCES were stored before they became arrays.
+		cc.Cast = `array_remove(regexp_split_to_array(trim(%[1]s), '\s*,\s*'), '')`
+	case to == "text":
+		cc.Cast = "%[1]s::text"
+	case from == "integer" && to == "real":
+		cc.Cast = "%[1]s::real"
+	case from == "real" && to == "integer":
+		cc.Cast = "%[1]s::integer"
+		cc.Invalid = "%[1]s <> trunc(%[1]s) OR abs(%[1]s) > 2147483647"
+	case from == "text" && to == "integer":
+		cc.Cast = "trim(%[1]s)::integer"
+		cc.Invalid = fmt.Sprintf("CASE WHEN trim(%%[1]s) ~ '%s' THEN trim(%%[1]s)::numeric NOT BETWEEN -2147483648 AND 2147483647 ELSE true END", integerPattern)
+	case from == "text" && to == "real":
+		cc.Cast = "trim(%[1]s)::real"
+		cc.Invalid = fmt.Sprintf("NOT (trim(%%[1]s) ~ '%s')", numericPattern)
+	case from == "text" && len(temporalPatterns[to]) > 0:
+		// Dates that match the pattern but do not exist, such as 2024-02-30, still fail the statement.
+		cc.Cast = "trim(%[1]s)::" + to
+		cc.Invalid = fmt.Sprintf("NOT (trim(%%[1]s) ~ '%s')", temporalPatterns[to])
+	case from == "text" && to == "jsonb":
+		// Text holding JSON is parsed, any other text becomes a JSON string.
+		cc.Cast = "try_jsonb(%[1]s)"
+		cc.Setup = QueryCreateTryJSONB()
+	case to == "jsonb":
+		// Existing values become JSON scalars.
+		cc.Cast = "to_jsonb(%[1]s)"
+	case from == "date" && to == "timestamptz", from == "timestamptz" && to == "date":
+		cc.Cast = "%[1]s::" + to
+	default:
+		return cc, false
+	}
+	return cc, true
+}
+
+// FindPropertyCast returns the conversion of a column of the value type previous, empty when unknown, to the type
+// of vt. Text becomes the HTML source of RICH_TEXT values, see QueryRichTextSources, and RICH_TEXT values become
+// their source.
+func FindPropertyCast(from string, previous, vt value_type.ValueType) (ColumnCast, bool) {
+	if vt == value_type.RichText && from == "text" {
+		return ColumnCast{From: from, To: vt.SQLType(), Cast: fmt.Sprintf("jsonb_build_object('format', '%s', 'source', %%[1]s)", value_type.RichTextHTML)}, true
+	}
+	if previous == value_type.RichText && from == "jsonb" && vt.SQLType() == "text" {
+		// Values written before RICH_TEXT was an object are a bare JSON string.
+		return ColumnCast{From: from, To: vt.SQLType(), Cast: "CASE WHEN jsonb_typeof(%[1]s) = 'object' THEN %[1]s ->> 'source' ELSE %[1]s #>> '{}' END"}, true
+	}
+	return FindColumnCast(from, vt.SQLType())
+}
+
+// UsingExpression returns the USING expression of the cast. When discardInvalid is true,
+// values that cannot be converted become NULL instead of failing the statement.
+func (cc ColumnCast) UsingExpression(column string, discardInvalid bool) string {
+	cast := fmt.Sprintf(cc.Cast, column)
+	if !discardInvalid || cc.Lossless() {
+		return cast
+	}
+	return fmt.Sprintf("CASE WHEN %s THEN NULL ELSE %s END", fmt.Sprintf(cc.Invalid, column), cast)
+}
+
+func QueryAlterColumnType(tid, pid string, cc ColumnCast, discardInvalid bool) string {
+	column := quoteColumn(pid)
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s;",
+		quoteTable(tid), column, cc.To, cc.UsingExpression(column, discardInvalid))
+}
+
+// QueryCountInvalidCast counts the rows whose value would be lost by the cast.
+// Without a known cast every non-null value is considered lost.
+func QueryCountInvalidCast(tid, pid string, cc ColumnCast, found bool) string {
+	column := quoteColumn(pid)
+	conditions := []string{fmt.Sprintf("%s IS NOT NULL", column)}
+	if found {
+		if cc.Lossless() {
+			return ""
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s)", fmt.Sprintf(cc.Invalid, column)))
+	}
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", quoteTable(tid), strings.Join(conditions, " AND "))
+}
+
+// IndexDef is an index managed by the schema loader, its name always starts with ManagedIndexPrefix.
+type IndexDef struct {
+	Name   string
+	Table  string
+	Column string
+	Method string
+	Unique bool
+}
+
+func ManagedIndexPrefix(table string) string {
+	return fmt.Sprintf("idx_%s_", table)
+}
+
+// DesiredIndexes returns the indexes a node type table should have according to its property types.
+func DesiredIndexes(tid string, propertyTypes []*node_type_model.PropertyType) []IndexDef {
+	table := strcase.ToSnake(tid)
+	var indexes []IndexDef
+	for _, pt := range propertyTypes {
+		vt, err := value_type.ParseValueType(pt.ValueType)
+		if err != nil {
+			continue
+		}
+		column := strcase.ToSnake(pt.PID)
+		switch vt {
+		case value_type.Reference:
+			indexes = append(indexes, IndexDef{
+				Name:   ManagedIndexPrefix(table) + column,
+				Table:  table,
+				Column: column,
+				Method: "btree",
+			})
+		case value_type.References:
+			indexes = append(indexes, IndexDef{
+				Name:   ManagedIndexPrefix(table) + column,
+				Table:  table,
+				Column: column,
+				Method: "gin",
+			})
+		case value_type.Slug:
+			// Soft-deleted records keep their slug so that they can be restored.
+			indexes = append(indexes, IndexDef{
+				Name:   SlugIndexName(table, column),
+				Table:  table,
+				Column: column,
+				Method: "btree",
+				Unique: true,
+			})
+		}
+	}
+	if len(SearchExpression(propertyTypes)) > 0 {
+		indexes = append(indexes, SearchIndex(table))
+	}
+	return indexes
+}
+
+// SlugIndexName names the unique index of a SLUG column, a write violating it means the slug is taken.
+func SlugIndexName(table, column string) string {
+	return ManagedIndexPrefix(table) + column + "_key"
+}
+
+const (
+	// SearchColumn is the generated tsvector column of the searchable properties.
+	SearchColumn = "search_vector"
+	// SearchConfig is the text search configuration, `simple` does not stem so it suits any language.
+	SearchConfig = "simple"
+)
+
+func SearchIndex(table string) IndexDef {
+	return IndexDef{Name: ManagedIndexPrefix(table) + SearchColumn, Table: table, Column: SearchColumn, Method: "gin"}
+}
+
+// SearchExpression returns the weighted tsvector of the searchable properties, empty when there are none.
+// Columns are sorted so that the expression only changes with the search definition.
+func SearchExpression(propertyTypes []*node_type_model.PropertyType) string {
+	weights := make(map[string]string)
+	documents := make(map[string]string)
+	columns := make([]string, 0)
+	for _, pt := range propertyTypes {
+		if !pt.Searchable {
+			continue
+		}
+		column := strcase.ToSnake(pt.PID)
+		documents[column] = searchDocument(QuoteIdentifier(column), value_type.ValueType(pt.ValueType))
+		weights[column] = pt.SearchWeight
+		if len(weights[column]) == 0 {
+			weights[column] = "D"
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	vectors := make([]string, len(columns))
+	for i, column := range columns {
+		vectors[i] = fmt.Sprintf("setweight(to_tsvector('%s', coalesce(%s, '')), '%s')", SearchConfig, documents[column], weights[column])
+	}
+	return strings.Join(vectors, " || ")
+}
+
+// searchDocument returns the text of a searchable column, the plain text of rich text.
+func searchDocument(column string, vt value_type.ValueType) string {
+	if vt == value_type.RichText {
+		return column + " ->> 'text'"
+	}
+	return column
+}
+
+func QueryAddSearchColumn(tid, expression string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s tsvector GENERATED ALWAYS AS (%s) STORED;", quoteTable(tid), QuoteIdentifier(SearchColumn), expression)
+}
+
+func QueryCreateIndex(index IndexDef) string {
+	kind := "INDEX"
+	if index.Unique {
+		kind = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("CREATE %s IF NOT EXISTS %s ON %s USING %s (%s);",
+		kind, QuoteIdentifier(index.Name), QuoteIdentifier(index.Table), index.Method, QuoteIdentifier(index.Column))
+}
+
+func QueryDropIndex(name string) string {
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s;", QuoteIdentifier(name))
+}
+
+// ForeignKeyDef is a foreign key managed by the schema loader, its name always starts with ManagedForeignKeyPrefix.
+type ForeignKeyDef struct {
+	Name     string
+	Table    string
+	Column   string
+	RefTable string
+	OnDelete string
+}
+
+func ManagedForeignKeyPrefix(table string) string {
+	return fmt.Sprintf("fk_%s_", table)
+}
+
+// foreignKeyActions maps onDelete to the ON DELETE action and its pg_constraint.confdeltype code.
+var foreignKeyActions = map[string]struct{ action, code string }{
+	node_type_model.OnDeleteRestrict: {"RESTRICT", "r"},
+	node_type_model.OnDeleteCascade:  {"CASCADE", "c"},
+	node_type_model.OnDeleteSetNull:  {"SET NULL", "n"},
+}
+
+// DesiredForeignKeys returns the foreign keys of the REFERENCE properties declaring onDelete.
+// REFERENCES are arrays, which Postgres cannot constrain, their onDelete is only enforced by the service.
+func DesiredForeignKeys(tid string, propertyTypes []*node_type_model.PropertyType) []ForeignKeyDef {
+	table := strcase.ToSnake(tid)
+	var foreignKeys []ForeignKeyDef
+	for _, pt := range propertyTypes {
+		if value_type.ValueType(pt.ValueType) != value_type.Reference || len(pt.OnDelete) == 0 || len(pt.ReferenceType) == 0 {
+			continue
+		}
+		column := strcase.ToSnake(pt.PID)
+		foreignKeys = append(foreignKeys, ForeignKeyDef{
+			Name:     ManagedForeignKeyPrefix(table) + column,
+			Table:    table,
+			Column:   column,
+			RefTable: strcase.ToSnake(pt.ReferenceType),
+			OnDelete: pt.OnDelete,
+		})
+	}
+	return foreignKeys
+}
+
+// Matches reports whether a live foreign key to refTable with the confdeltype code is the same as fk.
+func (fk ForeignKeyDef) Matches(refTable, code string) bool {
+	return fk.RefTable == refTable && foreignKeyActions[fk.OnDelete].code == code
+}
+
+func QueryAddForeignKey(fk ForeignKeyDef) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (id) ON DELETE %s;",
+		QuoteIdentifier(fk.Table), QuoteIdentifier(fk.Name), QuoteIdentifier(fk.Column), QuoteIdentifier(fk.RefTable), foreignKeyActions[fk.OnDelete].action)
+}
+
+func QueryDropForeignKey(table, name string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", QuoteIdentifier(table), QuoteIdentifier(name))
+}
+
+// QueryCountDangling counts the rows referencing records that do not exist, which would make adding fk fail.
+func QueryCountDangling(fk ForeignKeyDef) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s AS t WHERE %s", QuoteIdentifier(fk.Table), danglingCondition(fk))
+}
+
+func QueryClearDangling(fk ForeignKeyDef) string {
+	return fmt.Sprintf("UPDATE %s AS t SET %s = NULL WHERE %s;", QuoteIdentifier(fk.Table), QuoteIdentifier(fk.Column), danglingCondition(fk))
+}
+
+func danglingCondition(fk ForeignKeyDef) string {
+	return fmt.Sprintf("t.%[1]s IS NOT NULL AND NOT EXISTS (SELECT 1 FROM %[2]s AS r WHERE r.id = t.%[1]s)", QuoteIdentifier(fk.Column), QuoteIdentifier(fk.RefTable))
+}
+
+func QueryLiveForeignKeys() string {
+	return `
+		SELECT con.conname::text AS name, ref.relname::text AS ref_table, con.confdeltype::text AS on_delete
+		FROM pg_constraint con
+		JOIN pg_class rel ON rel.oid = con.conrelid
+		JOIN pg_class ref ON ref.oid = con.confrelid
+		JOIN pg_namespace ns ON ns.oid = rel.relnamespace
+		WHERE con.contype = 'f' AND ns.nspname = 'public' AND rel.relname = ?
+	`
+}
+
+func QueryLiveColumns() string {
+	return `
+		SELECT column_name, data_type, udt_name
+		FROM information_schema.columns
+		WHERE table_schema = 'public' AND table_name = ?
+	`
+}
+
+func QueryLiveIndexes() string {
+	return `
+		SELECT indexname
+		FROM pg_indexes
+		WHERE schemaname = 'public' AND tablename = ?
+	`
+}
+
+// NormalizeSQLType maps an information_schema data type to the type names used by MapValueTypeToSQL.
+func NormalizeSQLType(dataType, udtName string) string {
+	switch dataType {
+	case "ARRAY":
+		return strings.TrimPrefix(udtName, "_") + "[]"
+	case "character varying", "character":
+		return "text"
+	case "timestamp with time zone":
+		return "timestamptz"
+	case "time without time zone":
+		return "time"
+	case "USER-DEFINED":
+		return udtName
+	}
+	return dataType
+}
+
+func QueryCountNotNull(tid, pid string) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s IS NOT NULL", quoteTable(tid), quoteColumn(pid))
+}
+
+func QueryRenameColumn(tid, from, to string) string {
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", quoteTable(tid), quoteColumn(from), quoteColumn(to))
+}
+
+// QueryRenameReferenceValue points the REFERENCE properties showing the renamed property of tid to its new pid.
+func QueryRenameReferenceValue(tid, from, to string) string {
+	return fmt.Sprintf("UPDATE property_types SET reference_value = %s WHERE reference_type = %s AND reference_value = %s AND deleted_at IS NULL;",
+		QuoteLiteral(to), QuoteLiteral(tid), QuoteLiteral(from))
+}
+
+// QueryRenameEnumValue moves the records holding the old value of a renamed ENUM option to its new value.
+func QueryRenameEnumValue(tid, pid, from, to string) string {
+	return fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s;",
+		quoteTable(tid), quoteColumn(pid), QuoteLiteral(to), quoteColumn(pid), QuoteLiteral(from))
+}
+
+func QueryCountEnumValue(tid, pid, value string) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = %s", quoteTable(tid), quoteColumn(pid), QuoteLiteral(value))
+}
+
+// QueryCountInvalidEnum counts the rows holding a value that is not an option of the ENUM property.
+func QueryCountInvalidEnum(tid, pid string, options []string) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", quoteTable(tid), invalidEnumCondition(pid, options))
+}
+
+func QueryClearInvalidEnum(tid, pid string, options []string) string {
+	return fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s;", quoteTable(tid), quoteColumn(pid), invalidEnumCondition(pid, options))
+}
+
+func invalidEnumCondition(pid string, options []string) string {
+	values := make([]string, len(options))
+	for i, option := range options {
+		values[i] = QuoteLiteral(option)
+	}
+	return fmt.Sprintf("%[1]s IS NOT NULL AND %[1]s NOT IN (%[2]s)", quoteColumn(pid), strings.Join(values, ", "))
+}
+
+// QuerySlugRows selects the id, the value of slugColumn and the text of sourceColumn of every row, deleted ones
+// included, oldest first. A column that is not in the table yet is empty and selects NULL, sourceType is the SQL
+// type of sourceColumn.
+func QuerySlugRows(tid, slugColumn, sourceColumn, sourceType string) string {
+	slug, source := "NULL", "NULL"
+	if len(slugColumn) > 0 {
+		slug = QuoteIdentifier(slugColumn) + "::text"
+	}
+	switch {
+	case len(sourceColumn) == 0:
+	case sourceType == "jsonb":
+		// The plain text of a RICH_TEXT value, or its source when it has not been rendered.
+		source = fmt.Sprintf("coalesce(%[1]s ->> 'text', %[1]s ->> 'source')", QuoteIdentifier(sourceColumn))
+	default:
+		source = QuoteIdentifier(sourceColumn) + "::text"
+	}
+	return fmt.Sprintf("SELECT id, %s AS slug, %s AS source FROM %s ORDER BY created_at, id", slug, source, quoteTable(tid))
+}
+
+// QuerySetSlugs sets the slug of pid of each record id in slugs, an empty slug clears it.
+func QuerySetSlugs(tid, pid string, slugs map[string]string) string {
+	ids := make([]string, 0, len(slugs))
+	for id := range slugs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		slug := "NULL"
+		if len(slugs[id]) > 0 {
+			slug = QuoteLiteral(slugs[id])
+		}
+		values[i] = fmt.Sprintf("(%s, %s)", QuoteLiteral(id), slug)
+	}
+	return fmt.Sprintf("UPDATE %[1]s AS t SET %[2]s = v.slug FROM (VALUES %[3]s) AS v (id, slug) WHERE t.id = v.id;",
+		quoteTable(tid), quoteColumn(pid), strings.Join(values, ", "))
+}
+
+// QueryRichTextSources selects the RICH_TEXT values of pid that have not been rendered, which is the case of the
+// values converted from a text column.
+func QueryRichTextSources(tid, pid string) string {
+	return fmt.Sprintf("SELECT id, %[2]s ->> 'format' AS format, %[2]s ->> 'source' AS source FROM %[1]s WHERE jsonb_typeof(%[2]s) = 'object' AND %[2]s ->> 'html' IS NULL",
+		quoteTable(tid), quoteColumn(pid))
+}
+
+func QueryUpdateRichText(tid, pid string) string {
+	return fmt.Sprintf("UPDATE %s SET %s = ?::jsonb WHERE id = ?", quoteTable(tid), quoteColumn(pid))
+}
+
+// quoteTable and quoteColumn quote the table of tid and the column of pid, tids and pids are validated by the
+// node type model but every statement quotes them regardless.
+func quoteTable(tid string) string {
+	return QuoteIdentifier(strcase.ToSnake(tid))
+}
+
+func quoteColumn(pid string) string {
+	return QuoteIdentifier(strcase.ToSnake(pid))
+}
+
+func QuoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
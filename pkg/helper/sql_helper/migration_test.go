@@ -0,0 +1,187 @@
+package sql_helper
+
+import (
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindColumnCast(t *testing.T) {
+	cast, found := FindColumnCast("integer", "real")
+	assert.True(t, found)
+	assert.True(t, cast.Lossless())
+
+	cast, found = FindColumnCast("text", "integer")
+	assert.True(t, found)
+	assert.False(t, cast.Lossless())
+
+	_, found = FindColumnCast("real", "boolean")
+	assert.False(t, found)
+
+	cast, found = FindColumnCast("text", "text[]")
+	assert.True(t, found)
+	assert.Equal(t, `array_remove(regexp_split_to_array(trim(tags), '\s*,\s*'), '')`, cast.UsingExpression("tags", false))
+
+	cast, found = FindColumnCast("text[]", "text")
+	assert.True(t, found)
+	assert.Equal(t, "array_to_string(tags, ',')", cast.UsingExpression("tags", false))
+
+	cast, found = FindColumnCast("text", "date")
+	assert.True(t, found)
+	assert.Equal(t, "CASE WHEN NOT (trim(starts_on) ~ '^[0-9]{4}-[0-9]{2}-[0-9]{2}$') THEN NULL ELSE trim(starts_on)::date END", cast.UsingExpression("starts_on", true))
+
+	cast, found = FindColumnCast("date", "timestamptz")
+	assert.True(t, found)
+	assert.True(t, cast.Lossless())
+
+	cast, found = FindColumnCast("text", "jsonb")
+	assert.True(t, found)
+	assert.True(t, cast.Lossless())
+	assert.Equal(t, "try_jsonb(seo)", cast.UsingExpression("seo", true))
+	assert.Equal(t, QueryCreateTryJSONB(), cast.Setup)
+
+	cast, found = FindColumnCast("integer", "jsonb")
+	assert.True(t, found)
+	assert.Equal(t, "to_jsonb(rank)", cast.UsingExpression("rank", true))
+
+	cast, found = FindPropertyCast("text", value_type.String, value_type.RichText)
+	assert.True(t, found)
+	assert.Equal(t, "jsonb_build_object('format', 'html', 'source', description)", cast.UsingExpression("description", true))
+
+	cast, found = FindPropertyCast("jsonb", value_type.RichText, value_type.String)
+	assert.True(t, found)
+	assert.True(t, cast.Lossless())
+	assert.Equal(t, "CASE WHEN jsonb_typeof(description) = 'object' THEN description ->> 'source' ELSE description #>> '{}' END",
+		cast.UsingExpression("description", true))
+
+	cast, found = FindPropertyCast("jsonb", value_type.JSON, value_type.String)
+	assert.True(t, found)
+	assert.Equal(t, "seo::text", cast.UsingExpression("seo", true))
+
+	_, found = FindColumnCast("time", "date")
+	assert.False(t, found)
+}
+
+func TestQueryAlterColumnType(t *testing.T) {
+	cast, _ := FindColumnCast("real", "integer")
+
+	assert.Equal(t,
+		`ALTER TABLE "product" ALTER COLUMN "unit_price" TYPE integer USING "unit_price"::integer;`,
+		QueryAlterColumnType("product", "unitPrice", cast, false))
+	assert.Equal(t,
+		`ALTER TABLE "product" ALTER COLUMN "unit_price" TYPE integer USING CASE WHEN "unit_price" <> trunc("unit_price") OR abs("unit_price") > 2147483647 THEN NULL ELSE "unit_price"::integer END;`,
+		QueryAlterColumnType("product", "unitPrice", cast, true))
+}
+
+func TestQueryCountInvalidCast(t *testing.T) {
+	lossless, _ := FindColumnCast("integer", "text")
+	assert.Empty(t, QueryCountInvalidCast("product", "price", lossless, true))
+
+	lossy, _ := FindColumnCast("text", "real")
+	assert.Equal(t,
+		`SELECT COUNT(*) FROM "product" WHERE "price" IS NOT NULL AND (NOT (trim("price") ~ '^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$'))`,
+		QueryCountInvalidCast("product", "price", lossy, true))
+
+	assert.Equal(t,
+		`SELECT COUNT(*) FROM "product" WHERE "price" IS NOT NULL`,
+		QueryCountInvalidCast("product", "price", ColumnCast{}, false))
+}
+
+func TestNormalizeSQLType(t *testing.T) {
+	assert.Equal(t, "text", NormalizeSQLType("text", "text"))
+	assert.Equal(t, "text[]", NormalizeSQLType("ARRAY", "_text"))
+	assert.Equal(t, "timestamptz", NormalizeSQLType("timestamp with time zone", "timestamptz"))
+	assert.Equal(t, "real", NormalizeSQLType("real", "float4"))
+}
+
+func TestDesiredIndexes(t *testing.T) {
+	indexes := DesiredIndexes("productItem", []*node_type_model.PropertyType{
+		{PID: "productCategory", ValueType: "REFERENCE", ReferenceType: "productCategory"},
+		{PID: "name", ValueType: "STRING"},
+		{PID: "tags", ValueType: "REFERENCES", ReferenceType: "tag"},
+		{PID: "urlKey", ValueType: "SLUG", SlugSource: "name"},
+	})
+
+	assert.Equal(t, []IndexDef{
+		{Name: "idx_product_item_product_category", Table: "product_item", Column: "product_category", Method: "btree"},
+		{Name: "idx_product_item_tags", Table: "product_item", Column: "tags", Method: "gin"},
+		{Name: "idx_product_item_url_key_key", Table: "product_item", Column: "url_key", Method: "btree", Unique: true},
+	}, indexes)
+	assert.Equal(t, `CREATE INDEX IF NOT EXISTS "idx_product_item_product_category" ON "product_item" USING btree ("product_category");`, QueryCreateIndex(indexes[0]))
+	assert.Equal(t, `CREATE INDEX IF NOT EXISTS "idx_product_item_tags" ON "product_item" USING gin ("tags");`, QueryCreateIndex(indexes[1]))
+	assert.Equal(t, `CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_item_url_key_key" ON "product_item" USING btree ("url_key");`, QueryCreateIndex(indexes[2]))
+}
+
+func TestSearchExpression(t *testing.T) {
+	propertyTypes := []*node_type_model.PropertyType{
+		{PID: "title", ValueType: "STRING", Searchable: true, SearchWeight: "A"},
+		{PID: "body", ValueType: "RICH_TEXT", Searchable: true},
+		{PID: "slug", ValueType: "STRING"},
+	}
+
+	expression := SearchExpression(propertyTypes)
+	assert.Equal(t, `setweight(to_tsvector('simple', coalesce("body" ->> 'text', '')), 'D') || setweight(to_tsvector('simple', coalesce("title", '')), 'A')`, expression)
+	assert.Equal(t, `ALTER TABLE "article" ADD COLUMN "search_vector" tsvector GENERATED ALWAYS AS (`+expression+") STORED;", QueryAddSearchColumn("article", expression))
+	assert.Equal(t, []IndexDef{{Name: "idx_article_search_vector", Table: "article", Column: "search_vector", Method: "gin"}}, DesiredIndexes("article", propertyTypes))
+
+	assert.Empty(t, SearchExpression(propertyTypes[2:]))
+	assert.Empty(t, DesiredIndexes("article", propertyTypes[2:]))
+}
+
+func TestEnumQueries(t *testing.T) {
+	assert.Equal(t, `UPDATE "product" SET "sale_status" = 'live' WHERE "sale_status" = 'published';`, QueryRenameEnumValue("product", "saleStatus", "published", "live"))
+	assert.Equal(t, `SELECT COUNT(*) FROM "product" WHERE "sale_status" = 'it''s'`, QueryCountEnumValue("product", "saleStatus", "it's"))
+	assert.Equal(t,
+		`SELECT COUNT(*) FROM "product" WHERE "sale_status" IS NOT NULL AND "sale_status" NOT IN ('draft', 'live')`,
+		QueryCountInvalidEnum("product", "saleStatus", []string{"draft", "live"}))
+	assert.Equal(t,
+		`UPDATE "product" SET "sale_status" = NULL WHERE "sale_status" IS NOT NULL AND "sale_status" NOT IN ('draft');`,
+		QueryClearInvalidEnum("product", "saleStatus", []string{"draft"}))
+}
+
+func TestSlugQueries(t *testing.T) {
+	assert.Equal(t,
+		`SELECT id, "url_key"::text AS slug, "name"::text AS source FROM "product" ORDER BY created_at, id`,
+		QuerySlugRows("product", "url_key", "name", "text"))
+	assert.Equal(t,
+		`SELECT id, NULL AS slug, coalesce("body" ->> 'text', "body" ->> 'source') AS source FROM "article" ORDER BY created_at, id`,
+		QuerySlugRows("article", "", "body", "jsonb"))
+	assert.Equal(t,
+		`UPDATE "product" AS t SET "url_key" = v.slug FROM (VALUES ('a1', 'tee'), ('b2', NULL), ('c3', 'tee-2')) AS v (id, slug) WHERE t.id = v.id;`,
+		QuerySetSlugs("product", "urlKey", map[string]string{"c3": "tee-2", "a1": "tee", "b2": ""}))
+}
+
+func TestRichTextQueries(t *testing.T) {
+	assert.Equal(t,
+		`SELECT id, "body" ->> 'format' AS format, "body" ->> 'source' AS source FROM "article" WHERE jsonb_typeof("body") = 'object' AND "body" ->> 'html' IS NULL`,
+		QueryRichTextSources("article", "body"))
+	assert.Equal(t, `UPDATE "article" SET "body" = ?::jsonb WHERE id = ?`, QueryUpdateRichText("article", "body"))
+}
+
+func TestDesiredForeignKeys(t *testing.T) {
+	foreignKeys := DesiredForeignKeys("product", []*node_type_model.PropertyType{
+		{PID: "productCategory", ValueType: "REFERENCE", ReferenceType: "productCategory", OnDelete: "setNull"},
+		{PID: "brand", ValueType: "REFERENCE", ReferenceType: "brand"},
+		{PID: "tags", ValueType: "REFERENCES", ReferenceType: "tag", OnDelete: "cascade"},
+	})
+
+	assert.Equal(t, []ForeignKeyDef{{Name: "fk_product_product_category", Table: "product", Column: "product_category", RefTable: "product_category", OnDelete: "setNull"}}, foreignKeys)
+	assert.True(t, foreignKeys[0].Matches("product_category", "n"))
+	assert.False(t, foreignKeys[0].Matches("product_category", "r"))
+	assert.Equal(t,
+		`ALTER TABLE "product" ADD CONSTRAINT "fk_product_product_category" FOREIGN KEY ("product_category") REFERENCES "product_category" (id) ON DELETE SET NULL;`,
+		QueryAddForeignKey(foreignKeys[0]))
+	assert.Equal(t,
+		`SELECT COUNT(*) FROM "product" AS t WHERE t."product_category" IS NOT NULL AND NOT EXISTS (SELECT 1 FROM "product_category" AS r WHERE r.id = t."product_category")`,
+		QueryCountDangling(foreignKeys[0]))
+}
+
+func TestQueryRename(t *testing.T) {
+	assert.Equal(t, `ALTER TABLE "product_category" RENAME COLUMN "name" TO "display_name";`, QueryRenameColumn("productCategory", "name", "displayName"))
+	assert.Equal(t,
+		"UPDATE property_types SET reference_value = 'displayName' WHERE reference_type = 'productCategory' AND reference_value = 'name' AND deleted_at IS NULL;",
+		QueryRenameReferenceValue("productCategory", "name", "displayName"))
+	assert.Equal(t, "'it''s'", QuoteLiteral("it's"))
+}
@@ -0,0 +1,294 @@
+package sql_helper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+)
+
+// systemColumns are the columns every node type table has besides its property columns.
+var systemColumns = map[string]string{
+	"id":          "text",
+	"created_at":  "timestamptz",
+	"created_by":  "text",
+	"modified_at": "timestamptz",
+	"modified_by": "text",
+	"deleted_by":  "text",
+}
+
+// QueryField is a filter or sort field resolved against the schema.
+type QueryField struct {
+	Table  string
+	Column string
+	// PropertyType is nil for system columns.
+	PropertyType *shared_dto.PropertyTypeDTO
+	SQLType      string
+	// Expression replaces the column for computed fields such as the search rank.
+	Expression string
+}
+
+// SQL returns the quoted, table qualified column, or the expression filters and sorts compare.
+func (f QueryField) SQL() string {
+	if len(f.Expression) > 0 {
+		return f.Expression
+	}
+	return f.ColumnSQL()
+}
+
+// ColumnSQL returns the quoted, table qualified column, which projections select as stored.
+func (f QueryField) ColumnSQL() string {
+	return QuoteIdentifier(f.Table) + "." + QuoteIdentifier(f.Column)
+}
+
+// ValueType returns the value type of the property, or an empty string for system columns.
+func (f QueryField) ValueType() value_type.ValueType {
+	if f.PropertyType == nil {
+		return ""
+	}
+	return value_type.ValueType(f.PropertyType.ValueType)
+}
+
+// IsText reports whether the column holds a single text value that pattern operators can match.
+func (f QueryField) IsText() bool {
+	return f.SQLType == "text" && !f.IsMultiValued()
+}
+
+// IsMultiValued reports whether the column holds several values, matched with the contains operator.
+func (f QueryField) IsMultiValued() bool {
+	return f.ValueType() == value_type.References || strings.HasSuffix(f.SQLType, "[]")
+}
+
+type schemaTable struct {
+	table   string
+	columns map[string]*shared_dto.PropertyTypeDTO
+}
+
+func newSchemaTable(table string, propertyTypes []shared_dto.PropertyTypeDTO) schemaTable {
+	st := schemaTable{table: table, columns: make(map[string]*shared_dto.PropertyTypeDTO, len(propertyTypes))}
+	for i := range propertyTypes {
+		st.columns[strcase.ToSnake(propertyTypes[i].PID)] = &propertyTypes[i]
+	}
+	return st
+}
+
+func (st schemaTable) resolve(name string) (QueryField, bool) {
+	column := strcase.ToSnake(name)
+	if pt, ok := st.columns[column]; ok {
+		f := QueryField{Table: st.table, Column: column, PropertyType: pt, SQLType: value_type.ValueType(pt.ValueType).SQLType()}
+		if f.ValueType() == value_type.RichText {
+			// Rich text is filtered and sorted by its plain text.
+			f.Expression, f.SQLType = "("+searchDocument(f.ColumnSQL(), value_type.RichText)+")", "text"
+		}
+		return f, true
+	}
+	if sqlType, ok := systemColumns[column]; ok {
+		return QueryField{Table: st.table, Column: column, SQLType: sqlType}, true
+	}
+	return QueryField{}, false
+}
+
+// QuerySchema resolves the fields used by filters and sorting to the columns of a node type table
+// and of the reference tables joined to it. Unknown fields are rejected instead of reaching the SQL.
+type QuerySchema struct {
+	base  schemaTable
+	joins map[string]schemaTable
+
+	lookup   PropertyTypeLookup
+	maxDepth int
+	joined   []JoinTable
+}
+
+func NewQuerySchema(tid string, propertyTypes []shared_dto.PropertyTypeDTO) *QuerySchema {
+	return &QuerySchema{
+		base:  newSchemaTable(strcase.ToSnake(tid), propertyTypes),
+		joins: make(map[string]schemaTable),
+	}
+}
+
+// Join makes the fields of a joined reference table available as `<alias>.<field>`.
+func (qs *QuerySchema) Join(alias string, propertyTypes []shared_dto.PropertyTypeDTO) {
+	qs.joins[alias] = newSchemaTable(alias, propertyTypes)
+}
+
+// AutoJoin lets Resolve join the REFERENCE properties a `<reference>[.<nested>].<field>` goes through,
+// at most maxDepth levels deep. The joins are returned by JoinSQL.
+func (qs *QuerySchema) AutoJoin(lookup PropertyTypeLookup, maxDepth int) {
+	qs.lookup = lookup
+	qs.maxDepth = maxDepth
+}
+
+// JoinSQL returns the LEFT JOINs of the references Resolve joined automatically.
+func (qs *QuerySchema) JoinSQL() string {
+	return QueryJoin(JoinSpec{Tables: qs.joined})
+}
+
+// Resolve maps a field name, in camelCase or snake_case and optionally prefixed with the table
+// or a joined reference, to its column.
+func (qs *QuerySchema) Resolve(field string) (QueryField, error) {
+	parts := strings.Split(strings.TrimSpace(field), ".")
+	name := parts[len(parts)-1]
+	if len(parts) == 1 {
+		if f, ok := qs.base.resolve(name); ok {
+			return f, nil
+		}
+		return QueryField{}, fmt.Errorf("%w: unknown field %s", shared_dto.ErrInvalidQuery, field)
+	}
+	if len(parts) == 2 && strcase.ToSnake(parts[0]) == qs.base.table {
+		if f, ok := qs.base.resolve(name); ok {
+			return f, nil
+		}
+		return QueryField{}, fmt.Errorf("%w: unknown field %s", shared_dto.ErrInvalidQuery, field)
+	}
+	if f, ok := qs.base.resolve(parts[0]); ok && f.ValueType() == value_type.JSON {
+		return jsonPathField(f, parts[1:])
+	}
+
+	join, err := qs.joinPath(parts[:len(parts)-1])
+	if err != nil {
+		return QueryField{}, err
+	}
+	if f, ok := join.resolve(name); ok {
+		return f, nil
+	}
+	return QueryField{}, fmt.Errorf("%w: unknown field %s", shared_dto.ErrInvalidQuery, field)
+}
+
+var jsonKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// jsonPathField selects the text at path inside a JSON column, which the filter operators compare as text.
+func jsonPathField(f QueryField, path []string) (QueryField, error) {
+	keys := make([]string, len(path))
+	for i, key := range path {
+		if !jsonKeyPattern.MatchString(key) {
+			return QueryField{}, fmt.Errorf("%w: invalid key %q in the path of %s", shared_dto.ErrInvalidQuery, key, f.Column)
+		}
+		keys[i] = QuoteLiteral(key)
+	}
+	return QueryField{
+		Table:      f.Table,
+		Column:     f.Column,
+		SQLType:    "text",
+		Expression: fmt.Sprintf("(%s #>> ARRAY[%s])", f.SQL(), strings.Join(keys, ", ")),
+	}, nil
+}
+
+// joinPath returns the table reached by following the references of path, joining the ones that are not yet.
+func (qs *QuerySchema) joinPath(path []string) (schemaTable, error) {
+	if join, joined := qs.joins[strings.Join(path, ".")]; joined {
+		return join, nil
+	}
+	if qs.lookup == nil {
+		return schemaTable{}, fmt.Errorf("%w: %s is not a joined reference", shared_dto.ErrInvalidQuery, strings.Join(path, "."))
+	}
+	if len(path) > qs.maxDepth {
+		return schemaTable{}, fmt.Errorf("%w: %s goes %d references deep, at most %d are allowed", shared_dto.ErrInvalidQuery, strings.Join(path, "."), len(path), qs.maxDepth)
+	}
+
+	parent := qs.base
+	pids := make([]string, 0, len(path))
+	for _, segment := range path {
+		pt, exists := parent.columns[strcase.ToSnake(segment)]
+		if !exists {
+			return schemaTable{}, fmt.Errorf("%w: %s is not a property of %s", shared_dto.ErrInvalidQuery, segment, parent.table)
+		}
+		switch value_type.ValueType(pt.ValueType) {
+		case value_type.Reference:
+		case value_type.References:
+			return schemaTable{}, fmt.Errorf("%w: %s holds several references, filter it with contains, hasany or hasall", shared_dto.ErrInvalidQuery, segment)
+		default:
+			return schemaTable{}, fmt.Errorf("%w: %s is not a reference", shared_dto.ErrInvalidQuery, segment)
+		}
+		if len(pt.ReferenceType) == 0 {
+			return schemaTable{}, fmt.Errorf("%w: %s has no referenceType", shared_dto.ErrInvalidQuery, segment)
+		}
+
+		pids = append(pids, pt.PID)
+		alias := strings.Join(pids, ".")
+		join, joined := qs.joins[alias]
+		if !joined {
+			join = newSchemaTable(alias, qs.lookup(pt.ReferenceType))
+			qs.joins[alias] = join
+			qs.joined = append(qs.joined, JoinTable{
+				Name:     pt.ReferenceType,
+				JoinType: "LEFT",
+				Alias:    alias,
+				Conditions: []JoinCondition{
+					{Left: QuoteIdentifier(alias) + ".id", Op: "=", Right: QuoteIdentifier(parent.table) + "." + QuoteIdentifier(strcase.ToSnake(pt.PID))},
+					{Left: QuoteIdentifier(alias) + ".deleted_at", Op: "IS", Right: "NULL"},
+				},
+			})
+		}
+		parent = join
+	}
+	return parent, nil
+}
+
+// SortField is a resolved sort key. Rows without a value always come last.
+type SortField struct {
+	Field QueryField
+	Desc  bool
+}
+
+func (sf SortField) SQL() string {
+	if sf.Desc {
+		return sf.Field.SQL() + " DESC NULLS LAST"
+	}
+	return sf.Field.SQL() + " ASC NULLS LAST"
+}
+
+// ParseSort resolves `<field> [asc|desc], ...` into sort keys.
+func (qs *QuerySchema) ParseSort(sort string) ([]SortField, error) {
+	var sorts []SortField
+	for _, item := range strings.Split(sort, ",") {
+		parts := strings.Fields(item)
+		if len(parts) == 0 {
+			continue
+		}
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("%w: invalid sort %q", shared_dto.ErrInvalidQuery, strings.TrimSpace(item))
+		}
+
+		field, err := qs.Resolve(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		sf := SortField{Field: field}
+		if len(parts) == 2 {
+			switch strings.ToLower(parts[1]) {
+			case "asc":
+			case "desc":
+				sf.Desc = true
+			default:
+				return nil, fmt.Errorf("%w: invalid sort direction %q, expected asc or desc", shared_dto.ErrInvalidQuery, parts[1])
+			}
+		}
+		sorts = append(sorts, sf)
+	}
+	return sorts, nil
+}
+
+// CompileSort turns `<field> [asc|desc], ...` into an ORDER BY clause of quoted columns.
+func (qs *QuerySchema) CompileSort(sort string) (string, error) {
+	sorts, err := qs.ParseSort(sort)
+	if err != nil {
+		return "", err
+	}
+	return compileOrderBy(sorts), nil
+}
+
+func compileOrderBy(sorts []SortField) string {
+	clauses := make([]string, 0, len(sorts))
+	for _, sf := range sorts {
+		clauses = append(clauses, sf.SQL())
+	}
+	return strings.Join(clauses, ", ")
+}
+
+// QuoteIdentifier quotes a table or column name for PostgreSQL.
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
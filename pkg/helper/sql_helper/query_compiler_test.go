@@ -0,0 +1,207 @@
+package sql_helper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+	"github.com/stretchr/testify/assert"
+)
+
+func productQuerySchema() *QuerySchema {
+	schema := NewQuerySchema("product", []shared_dto.PropertyTypeDTO{
+		{PID: "name", ValueType: "STRING"},
+		{PID: "unitPrice", ValueType: "DOUBLE"},
+		{PID: "stock", ValueType: "INT"},
+		{PID: "brand", ValueType: "REFERENCE", ReferenceType: "brand"},
+	})
+	schema.Join("brand", []shared_dto.PropertyTypeDTO{{PID: "title", ValueType: "STRING"}})
+	return schema
+}
+
+func TestQuerySchema_Resolve(t *testing.T) {
+	schema := productQuerySchema()
+
+	for field, expected := range map[string]string{
+		"unitPrice":         `"product"."unit_price"`,
+		"unit_price":        `"product"."unit_price"`,
+		"product.unitPrice": `"product"."unit_price"`,
+		"createdAt":         `"product"."created_at"`,
+		"brand.title":       `"brand"."title"`,
+	} {
+		f, err := schema.Resolve(field)
+		assert.NoError(t, err, field)
+		assert.Equal(t, expected, f.SQL(), field)
+	}
+
+	for _, field := range []string{"price", "name; DROP TABLE product", "brand.missing", "category.title", "a.b.c"} {
+		_, err := schema.Resolve(field)
+		assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery), field)
+	}
+}
+
+func TestQuerySchema_AutoJoin(t *testing.T) {
+	schema := NewQuerySchema("product", expansionPropertyTypes["product"])
+	schema.AutoJoin(func(tid string) []shared_dto.PropertyTypeDTO { return expansionPropertyTypes[tid] }, 2)
+
+	orderBy, err := schema.CompileSort("brand.title desc, brand.parentBrand.title")
+	assert.NoError(t, err)
+	assert.Equal(t, `"brand"."title" DESC NULLS LAST, "brand.parentBrand"."title" ASC NULLS LAST`, orderBy)
+
+	field, err := schema.Resolve("brand.title")
+	assert.NoError(t, err)
+	assert.Equal(t, `"brand"."title"`, field.SQL())
+
+	assert.Equal(t,
+		` LEFT JOIN "brand" AS "brand" ON "brand".id = "product"."brand" AND "brand".deleted_at IS NULL`+
+			` LEFT JOIN "brand" AS "brand.parentBrand" ON "brand.parentBrand".id = "brand"."parent_brand" AND "brand.parentBrand".deleted_at IS NULL`,
+		schema.JoinSQL())
+
+	for _, field := range []string{"tags.label", "name.title", "missing.title", "brand.missing", "brand.parentBrand.parentBrand.title"} {
+		_, err := schema.Resolve(field)
+		assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery), field)
+	}
+}
+
+func TestQuerySchema_CompileSort(t *testing.T) {
+	schema := productQuerySchema()
+
+	orderBy, err := schema.CompileSort("unitPrice desc, name,brand.title ASC")
+	assert.NoError(t, err)
+	assert.Equal(t, `"product"."unit_price" DESC NULLS LAST, "product"."name" ASC NULLS LAST, "brand"."title" ASC NULLS LAST`, orderBy)
+
+	_, err = schema.CompileSort("name sideways")
+	assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery))
+
+	_, err = schema.CompileSort("(SELECT 1) desc")
+	assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery))
+}
+
+func TestBuildSearchConditions(t *testing.T) {
+	schema := productQuerySchema()
+
+	where, values, err := BuildSearchConditions(schema, []shared_utils.SearchQuery{
+		{Field: "stock", Operator: "from", Value: "5"},
+		{Field: "brand.title", Operator: "include", Value: "acme"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `"product"."stock" >= ? AND "brand"."title"::text ILIKE ?`, where)
+	assert.Equal(t, []interface{}{int64(5), "%acme%"}, values)
+
+	_, _, err = BuildSearchConditions(schema, []shared_utils.SearchQuery{{Field: "stock", Operator: "equal", Value: "many"}})
+	assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery))
+}
+
+func TestBuildSearchConditions_Temporal(t *testing.T) {
+	schema := NewQuerySchema("event", []shared_dto.PropertyTypeDTO{
+		{PID: "startsOn", ValueType: "DATE"},
+		{PID: "startsAt", ValueType: "DATETIME"},
+	})
+
+	where, values, err := BuildSearchConditions(schema, []shared_utils.SearchQuery{{Field: "startsOn", Operator: "fromto", Value: "2024-01-01,2024-01-31T10:00:00Z"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `"event"."starts_on" BETWEEN ? AND ?`, where)
+	assert.Equal(t, []interface{}{"2024-01-01", "2024-01-31"}, values)
+
+	for _, field := range []string{"startsAt", "createdAt"} {
+		_, values, err = BuildSearchConditions(schema, []shared_utils.SearchQuery{{Field: field, Operator: "from", Value: "now-7d"}})
+		assert.NoError(t, err, field)
+		assert.WithinDuration(t, time.Now().AddDate(0, 0, -7), values[0].(time.Time), time.Minute, field)
+	}
+
+	_, _, err = BuildSearchConditions(schema, []shared_utils.SearchQuery{{Field: "startsOn", Operator: "from", Value: "last week"}})
+	assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery))
+}
+
+func TestBuildSearchConditions_JSONPath(t *testing.T) {
+	schema := NewQuerySchema("product", []shared_dto.PropertyTypeDTO{{PID: "seo", ValueType: "JSON"}})
+
+	where, values, err := BuildSearchConditions(schema, []shared_utils.SearchQuery{{Field: "seo.meta.title", Operator: "equal", Value: "Shoes"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `("product"."seo" #>> ARRAY['meta', 'title']) = ?`, where)
+	assert.Equal(t, []interface{}{"Shoes"}, values)
+
+	where, _, err = BuildSearchConditions(schema, []shared_utils.SearchQuery{{Field: "seo.title", Operator: "startswith", Value: "Sh"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `("product"."seo" #>> ARRAY['title']) ILIKE ?`, where)
+
+	for _, field := range []string{"seo.ti'tle", "seo.a?b"} {
+		_, err = schema.Resolve(field)
+		assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery), field)
+	}
+}
+
+func TestBuildSearchConditions_RichText(t *testing.T) {
+	schema := NewQuerySchema("product", []shared_dto.PropertyTypeDTO{{PID: "description", ValueType: "RICH_TEXT"}})
+
+	where, values, err := BuildSearchConditions(schema, []shared_utils.SearchQuery{{Field: "description", Operator: "include", Value: "cotton"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `("product"."description" ->> 'text')::text ILIKE ?`, where)
+	assert.Equal(t, []interface{}{"%cotton%"}, values)
+
+	selectFields, err := BuildProjection(schema, "product", []string{"description"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `"product"."id", "product"."description"`, selectFields)
+}
+
+func TestBuildFilterExpression(t *testing.T) {
+	filter, err := shared_utils.ParseFilterExpression("(name_equal:shoe OR brand.title_equal:acme) AND NOT stock_to:0")
+	assert.NoError(t, err)
+
+	where, values, err := BuildFilterExpression(productQuerySchema(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, `(("product"."name" = ? OR "brand"."title" = ?) AND NOT ("product"."stock" <= ?))`, where)
+	assert.Equal(t, []interface{}{"shoe", "acme", int64(0)}, values)
+
+	filter, _ = shared_utils.ParseFilterExpression("name_equal:shoe OR price_equal:1")
+	_, _, err = BuildFilterExpression(productQuerySchema(), filter)
+	assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery))
+}
+
+func TestBuildSearchConditions_Operators(t *testing.T) {
+	schema := NewQuerySchema("article", []shared_dto.PropertyTypeDTO{
+		{PID: "title", ValueType: "STRING"},
+		{PID: "views", ValueType: "INT"},
+		{PID: "tags", ValueType: "REFERENCES", ReferenceType: "tag"},
+	})
+
+	for _, tc := range []struct {
+		query  shared_utils.SearchQuery
+		where  string
+		values []interface{}
+	}{
+		{shared_utils.SearchQuery{Field: "views", Operator: "notequal", Value: "3"}, `"article"."views" IS DISTINCT FROM ?`, []interface{}{int64(3)}},
+		{shared_utils.SearchQuery{Field: "views", Operator: "notin", Value: "1,2"}, `("article"."views" IS NULL OR "article"."views" NOT IN ?)`, []interface{}{[]interface{}{int64(1), int64(2)}}},
+		{shared_utils.SearchQuery{Field: "title", Operator: "isnull"}, `"article"."title" IS NULL`, nil},
+		{shared_utils.SearchQuery{Field: "createdBy", Operator: "notnull"}, `"article"."created_by" IS NOT NULL`, nil},
+		{shared_utils.SearchQuery{Field: "title", Operator: "startswith", Value: "100%_"}, `"article"."title" ILIKE ?`, []interface{}{`100\%\_%`}},
+		{shared_utils.SearchQuery{Field: "title", Operator: "endswith", Value: "go"}, `"article"."title" ILIKE ?`, []interface{}{"%go"}},
+		{shared_utils.SearchQuery{Field: "title", Operator: "like", Value: "Go%"}, `"article"."title" LIKE ?`, []interface{}{"Go%"}},
+		{shared_utils.SearchQuery{Field: "title", Operator: "regex", Value: "^[A-Z]"}, `"article"."title" ~ ?`, []interface{}{"^[A-Z]"}},
+		{shared_utils.SearchQuery{Field: "tags", Operator: "contains", Value: "go"}, `? = ANY("article"."tags")`, []interface{}{"go"}},
+		{shared_utils.SearchQuery{Field: "tags", Operator: "hasany", Value: "go, rust"}, `"article"."tags" && ?`, []interface{}{value_type.TextArray{"go", "rust"}}},
+		{shared_utils.SearchQuery{Field: "tags", Operator: "hasall", Value: "go,rust"}, `"article"."tags" @> ?`, []interface{}{value_type.TextArray{"go", "rust"}}},
+	} {
+		where, values, err := BuildSearchConditions(schema, []shared_utils.SearchQuery{tc.query})
+		assert.NoError(t, err, tc.query.Operator)
+		assert.Equal(t, tc.where, where, tc.query.Operator)
+		assert.Equal(t, tc.values, values, tc.query.Operator)
+	}
+
+	for _, query := range []shared_utils.SearchQuery{
+		{Field: "views", Operator: "startswith", Value: "1"},
+		{Field: "views", Operator: "regex", Value: "1"},
+		{Field: "title", Operator: "contains", Value: "go"},
+		{Field: "tags", Operator: "like", Value: "go"},
+		{Field: "tags", Operator: "equal", Value: "go"},
+		{Field: "tags", Operator: "in", Value: "go"},
+		{Field: "title", Operator: "hasany", Value: "go"},
+		{Field: "tags", Operator: "hasall", Value: " , "},
+	} {
+		_, _, err := BuildSearchConditions(schema, []shared_utils.SearchQuery{query})
+		assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery), query.Operator)
+	}
+}
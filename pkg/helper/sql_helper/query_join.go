@@ -1,11 +1,9 @@
 package sql_helper
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/iancoleman/strcase"
 	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
-	shared_utils "github.com/ledaian41/go-cms-service/pkg/shared/utils"
 	"strings"
 )
 
@@ -20,44 +18,16 @@ type JoinTable struct {
 	JoinType   string
 	Conditions []JoinCondition
 	Alias      string
-	Fields     []string
 }
 
 type JoinSpec struct {
 	Tables []JoinTable
 }
 
-func NewJoinSpec(tid string, propertyTypes []shared_dto.PropertyTypeDTO) JoinSpec {
-	spec := JoinSpec{
-		Tables: make([]JoinTable, 0, len(propertyTypes)),
-	}
-	for _, pt := range propertyTypes {
-		if len(pt.ReferenceType) == 0 {
-			continue
-		}
-
-		joinTable := JoinTable{
-			Name:     pt.ReferenceType,
-			JoinType: "LEFT",
-			Conditions: []JoinCondition{
-				{
-					Left:  fmt.Sprintf("%s.%s", tid, pt.PID),
-					Op:    "=",
-					Right: fmt.Sprintf("%s.id", pt.PID),
-				},
-			},
-			Alias:  pt.PID,
-			Fields: []string{},
-		}
-		spec.Tables = append(spec.Tables, joinTable)
-	}
-	return spec
-}
-
 func QueryJoin(spec JoinSpec) string {
 	query := ""
 	for _, table := range spec.Tables {
-		query += fmt.Sprintf("%s JOIN %s AS %s ON ", table.JoinType, strcase.ToSnake(table.Name), table.Alias)
+		query += fmt.Sprintf(" %s JOIN %s AS %s ON ", table.JoinType, QuoteIdentifier(strcase.ToSnake(table.Name)), QuoteIdentifier(table.Alias))
 		conditions := make([]string, 0)
 
 		for _, cond := range table.Conditions {
@@ -70,66 +40,47 @@ func QueryJoin(spec JoinSpec) string {
 	return query
 }
 
-func BuildSelectFields(typeId string, spec JoinSpec) string {
-	var fields []string
-
-	fields = append(fields, fmt.Sprintf("%s.*", typeId))
-
-	for _, table := range spec.Tables {
-		if len(table.Fields) == 0 {
-			fields = append(fields, fmt.Sprintf("row_to_json(%s.*) as %s", table.Alias, table.Alias))
-		} else {
-			for _, field := range table.Fields {
-				fields = append(fields, fmt.Sprintf("%s.%s as %s_%s",
-					table.Alias,
-					field,
-					table.Alias,
-					field))
-			}
-		}
+// BuildProjection selects only the given fields of the main table, `<reference>.<field>` entries
+// limit the columns of the expanded references instead. The id of the main table is always selected.
+func BuildProjection(schema *QuerySchema, typeId string, fields []string, expansions []*Expansion) (string, error) {
+	if len(fields) == 0 {
+		return fmt.Sprintf("%s.*", QuoteIdentifier(typeId)), nil
 	}
 
-	return strings.Join(fields, ", ")
-
-}
-
-func FormatJoinResponse(records []map[string]interface{}, spec JoinSpec) []map[string]interface{} {
-	formattedRecords := make([]map[string]interface{}, len(records))
-	for i, record := range records {
-		formattedRecord := make(map[string]interface{})
-
-		for key, value := range record {
-			handled := false
-
-			for _, join := range spec.Tables {
-				prefix := join.Alias + "_"
-				if strings.HasPrefix(key, prefix) {
-					tableName := join.Name
-					if _, exists := formattedRecord[tableName]; !exists {
-						formattedRecord[tableName] = make(map[string]interface{})
-					}
-
-					fieldName := strings.TrimPrefix(key, prefix)
-					formattedRecord[tableName].(map[string]interface{})[fieldName] = value
-					handled = true
-					break
-				}
-				strValue, isString := value.(string)
-				if key == join.Alias && isString && shared_utils.IsJSON(strValue) {
-					if err := json.Unmarshal([]byte(strValue), &value); err == nil {
-						formattedRecord[key] = value
-					}
-					handled = true
-					break
-				}
+	id, _ := schema.Resolve("id")
+	columns := []string{id.SQL()}
+	selected := map[string]bool{id.SQL(): true}
+	for _, name := range fields {
+		if path, field, found := cutLast(name, "."); found {
+			expansion := FindExpansion(expansions, path)
+			if expansion == nil {
+				return "", fmt.Errorf("%w: %s is not an expanded reference", shared_dto.ErrInvalidQuery, path)
 			}
-
-			if !handled {
-				formattedRecord[key] = value
+			if err := expansion.Project(field); err != nil {
+				return "", err
 			}
+			continue
 		}
-		formattedRecords[i] = formattedRecord
+
+		field, err := schema.Resolve(name)
+		if err != nil {
+			return "", err
+		}
+		if field.Table != strcase.ToSnake(typeId) {
+			return "", fmt.Errorf("%w: unknown field %s", shared_dto.ErrInvalidQuery, name)
+		}
+		if selected[field.ColumnSQL()] {
+			continue
+		}
+		selected[field.ColumnSQL()] = true
+		columns = append(columns, field.ColumnSQL())
 	}
+	return strings.Join(columns, ", "), nil
+}
 
-	return formattedRecords
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
 }
@@ -0,0 +1,30 @@
+package sql_helper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildProjection(t *testing.T) {
+	expansions := productExpansions(t, "brand")
+	selectFields, err := BuildProjection(productQuerySchema(), "product", []string{"name", "unitPrice", "brand.title", "id"}, expansions)
+	assert.NoError(t, err)
+	assert.Equal(t, `"product"."id", "product"."name", "product"."unit_price"`, selectFields)
+	assert.Equal(t, []string{"id", "title"}, expansions[0].Fields)
+
+	selectFields, err = BuildProjection(productQuerySchema(), "product", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `"product".*`, selectFields)
+
+	_, err = BuildProjection(productQuerySchema(), "product", []string{"name", "secret"}, nil)
+	assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery))
+
+	_, err = BuildProjection(productQuerySchema(), "product", []string{"brand.secret"}, productExpansions(t, "brand"))
+	assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery))
+
+	_, err = BuildProjection(productQuerySchema(), "product", []string{"brand.title"}, nil)
+	assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery))
+}
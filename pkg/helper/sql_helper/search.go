@@ -0,0 +1,102 @@
+package sql_helper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+)
+
+const (
+	// highlightColumnPrefix names the columns holding the snippets until FormatHighlights nests them under HighlightKey.
+	highlightColumnPrefix = "__highlight_"
+	HighlightKey          = "_highlight"
+	highlightStart        = "<mark>"
+	highlightStop         = "</mark>"
+	// searchQueryAlias names the parsed query, joined once so that the condition, the rank and the snippets
+	// refer to it without parameters of their own.
+	searchQueryAlias = "__search_query"
+)
+
+// FullTextSearch matches the records whose search column matches a web search style query
+// (`"exact phrase"`, `or`, `-excluded`).
+type FullTextSearch struct {
+	table   string
+	columns []string
+	// documents are the texts of the columns, see searchDocument.
+	documents map[string]string
+	text      string
+	query     string
+}
+
+// NewFullTextSearch prepares the search of the base table of schema, which needs searchable properties.
+func NewFullTextSearch(schema *QuerySchema, query string) (*FullTextSearch, error) {
+	fts := &FullTextSearch{table: schema.base.table, documents: make(map[string]string), text: strings.TrimSpace(query)}
+	for column, pt := range schema.base.columns {
+		if pt.Searchable {
+			fts.columns = append(fts.columns, column)
+			fts.documents[column] = searchDocument(QuoteIdentifier(fts.table)+"."+QuoteIdentifier(column), value_type.ValueType(pt.ValueType))
+		}
+	}
+	if len(fts.columns) == 0 {
+		return nil, fmt.Errorf("%w: %s has no searchable properties", shared_dto.ErrInvalidQuery, schema.base.table)
+	}
+	sort.Strings(fts.columns)
+
+	// The rank is a keyset sort key, which has no parameters, so the expressions refer to the joined query.
+	fts.query = QuoteIdentifier(searchQueryAlias)
+	return fts, nil
+}
+
+// Join parses the search text, it must be joined before Condition, Rank and HighlightColumns are used.
+func (fts *FullTextSearch) Join() (string, []interface{}) {
+	return fmt.Sprintf("CROSS JOIN websearch_to_tsquery('%s', ?) AS %s", SearchConfig, fts.query), []interface{}{fts.text}
+}
+
+func (fts *FullTextSearch) Condition() string {
+	return fmt.Sprintf("%s.%s @@ %s", QuoteIdentifier(fts.table), QuoteIdentifier(SearchColumn), fts.query)
+}
+
+// Rank sorts the best matches first. It is cast to double precision so that it survives the cursor round trip exactly.
+func (fts *FullTextSearch) Rank() SortField {
+	return SortField{
+		Field: QueryField{
+			Expression: fmt.Sprintf("ts_rank(%s.%s, %s)::double precision", QuoteIdentifier(fts.table), QuoteIdentifier(SearchColumn), fts.query),
+			SQLType:    "double precision",
+		},
+		Desc: true,
+	}
+}
+
+// HighlightColumns selects a snippet of every searchable property with the matches wrapped in <mark>.
+func (fts *FullTextSearch) HighlightColumns() string {
+	columns := make([]string, len(fts.columns))
+	for i, column := range fts.columns {
+		columns[i] = fmt.Sprintf("ts_headline('%s', coalesce(%s, ''), %s, 'StartSel=%s, StopSel=%s') AS %s",
+			SearchConfig, fts.documents[column], fts.query, highlightStart, highlightStop,
+			QuoteIdentifier(highlightColumnPrefix+column))
+	}
+	return strings.Join(columns, ", ")
+}
+
+// FormatHighlights moves the snippets containing a match under HighlightKey and drops the others.
+func FormatHighlights(records []map[string]interface{}) {
+	for _, record := range records {
+		highlights := make(map[string]interface{})
+		for key, value := range record {
+			column, found := strings.CutPrefix(key, highlightColumnPrefix)
+			if !found {
+				continue
+			}
+			delete(record, key)
+			if snippet, isString := value.(string); isString && strings.Contains(snippet, highlightStart) {
+				highlights[column] = snippet
+			}
+		}
+		if len(highlights) > 0 {
+			record[HighlightKey] = highlights
+		}
+	}
+}
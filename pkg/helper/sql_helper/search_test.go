@@ -0,0 +1,47 @@
+package sql_helper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullTextSearch(t *testing.T) {
+	schema := NewQuerySchema("article", []shared_dto.PropertyTypeDTO{
+		{PID: "title", ValueType: "STRING", Searchable: true},
+		{PID: "slug", ValueType: "STRING"},
+	})
+
+	search, err := NewFullTextSearch(schema, ` "go cms" -draft's?`)
+	assert.NoError(t, err)
+	join, values := search.Join()
+	assert.Equal(t, `CROSS JOIN websearch_to_tsquery('simple', ?) AS "__search_query"`, join)
+	assert.Equal(t, []interface{}{`"go cms" -draft's?`}, values)
+	query := `"__search_query"`
+	assert.Equal(t, `"article"."search_vector" @@ `+query, search.Condition())
+
+	rank := search.Rank()
+	assert.True(t, rank.Desc)
+	assert.Equal(t, `ts_rank("article"."search_vector", `+query+`)::double precision`, rank.Field.SQL())
+	assert.Equal(t,
+		`ts_headline('simple', coalesce("article"."title", ''), `+query+`, 'StartSel=<mark>, StopSel=</mark>') AS "__highlight_title"`,
+		search.HighlightColumns())
+
+	_, err = NewFullTextSearch(NewQuerySchema("tag", []shared_dto.PropertyTypeDTO{{PID: "label", ValueType: "STRING"}}), "go")
+	assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery))
+}
+
+func TestFormatHighlights(t *testing.T) {
+	records := []map[string]interface{}{
+		{"id": "1", "__highlight_title": "Learn <mark>Go</mark>", "__highlight_body": "nothing here"},
+		{"id": "2", "__highlight_title": "Rust"},
+	}
+
+	FormatHighlights(records)
+	assert.Equal(t, []map[string]interface{}{
+		{"id": "1", HighlightKey: map[string]interface{}{"title": "Learn <mark>Go</mark>"}},
+		{"id": "2"},
+	}, records)
+}
@@ -5,10 +5,11 @@ import (
 	"hash/fnv"
 	"math/big"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/snowflake"
-	"github.com/iancoleman/strcase"
 	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
 	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
 	"github.com/ledaian41/go-cms-service/pkg/value_type"
 )
@@ -27,7 +28,7 @@ func GenerateID() string {
 }
 
 func QueryCreateNewTable(nodeType *node_type_model.NodeType) string {
-	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, created_at timestamptz DEFAULT NOW(), created_by text, modified_at timestamptz DEFAULT NOW(), modified_by text, deleted_at timestamptz, deleted_by text, ", strcase.ToSnake(nodeType.TID))
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, created_at timestamptz DEFAULT NOW(), created_by text, modified_at timestamptz DEFAULT NOW(), modified_by text, deleted_at timestamptz, deleted_by text, ", quoteTable(nodeType.TID))
 	var columnDefs []string
 
 	for _, pt := range nodeType.PropertyTypes {
@@ -35,7 +36,7 @@ func QueryCreateNewTable(nodeType *node_type_model.NodeType) string {
 		if len(sqlType) == 0 {
 			continue
 		}
-		columnDefs = append(columnDefs, fmt.Sprintf("%s %s", strcase.ToSnake(pt.PID), sqlType))
+		columnDefs = append(columnDefs, fmt.Sprintf("%s %s", quoteColumn(pt.PID), sqlType))
 	}
 	query += strings.Join(columnDefs, ", ") + ");"
 	return query
@@ -47,68 +48,213 @@ func QueryAddColumnToTable(tid string, pt *node_type_model.PropertyType) string
 		return ""
 	}
 	query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;",
-		tid, pt.PID, sqlType)
+		quoteTable(tid), quoteColumn(pt.PID), sqlType)
 	return query
 }
 
 func QueryDeleteColumnFromTable(tid, pid string) string {
-	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", strcase.ToSnake(tid), strcase.ToSnake(pid))
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quoteTable(tid), quoteColumn(pid))
 }
 
 func QueryTableColumns(tid string) string {
 	return fmt.Sprintf(`
 		SELECT column_name
 		FROM information_schema.columns
-		WHERE table_schema = 'public' AND table_name = '%s'
+		WHERE table_schema = 'public' AND table_name = %s
 		ORDER BY ordinal_position
-	`, tid)
+	`, QuoteLiteral(tid))
 }
 
 func QueryTableExist(tid string) string {
 	return fmt.Sprintf(`
 		SELECT EXISTS (
 			SELECT FROM information_schema.tables
-			WHERE table_schema = 'public' AND table_name = '%s'
+			WHERE table_schema = 'public' AND table_name = %s
 		)
-	`, tid)
+	`, QuoteLiteral(tid))
 }
 
-func BuildSearchConditions(queries []shared_utils.SearchQuery) (string, []interface{}) {
+// BuildSearchConditions compiles the search queries into a parameterized WHERE clause. Fields are resolved
+// against the schema and values are converted to the value type of their property.
+func BuildSearchConditions(schema *QuerySchema, queries []shared_utils.SearchQuery) (string, []interface{}, error) {
 	var conditions []string
 	var values []interface{}
 
 	for _, query := range queries {
-		switch query.Operator {
-		case "equal":
-			conditions = append(conditions, fmt.Sprintf("%s = ?", query.Field))
-			values = append(values, query.Value)
-		case "include":
-			conditions = append(conditions, fmt.Sprintf("%s ILIKE ?", query.Field))
-			values = append(values, fmt.Sprintf("%%%s%%", strings.TrimSpace(query.Value)))
-		case "in":
-			conditions = append(conditions, fmt.Sprintf("%s IN ?", query.Field))
-			queryValue := strings.Split(query.Value, ",")
-			interfaceValues := make([]interface{}, len(queryValue))
-			for i, v := range queryValue {
-				interfaceValues[i] = v
-			}
-			values = append(values, interfaceValues)
-		case "from":
-			conditions = append(conditions, fmt.Sprintf("%s >= ?", query.Field))
-			values = append(values, query.Value)
-		case "to":
-			conditions = append(conditions, fmt.Sprintf("%s <= ?", query.Field))
-			values = append(values, query.Value)
-		case "fromto":
-			fromTo := strings.Split(query.Value, ",")
-			conditions = append(conditions, fmt.Sprintf("%s BETWEEN ? AND ?", query.Field))
-			values = append(values, strings.TrimSpace(fromTo[0]), strings.TrimSpace(fromTo[1]))
+		condition, conditionValues, err := buildSearchCondition(schema, query)
+		if err != nil {
+			return "", nil, err
 		}
+		conditions = append(conditions, condition)
+		values = append(values, conditionValues...)
 	}
 
 	if len(conditions) == 0 {
-		return "", nil
+		return "", nil, nil
 	}
 
-	return strings.Join(conditions, " AND "), values
+	return strings.Join(conditions, " AND "), values, nil
+}
+
+// BuildFilterExpression compiles a parsed filter expression into a parameterized condition.
+func BuildFilterExpression(schema *QuerySchema, node *shared_utils.FilterNode) (string, []interface{}, error) {
+	if node.Query != nil {
+		return buildSearchCondition(schema, *node.Query)
+	}
+
+	var conditions []string
+	var values []interface{}
+	for _, child := range node.Children {
+		condition, childValues, err := BuildFilterExpression(schema, child)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, condition)
+		values = append(values, childValues...)
+	}
+
+	switch node.Op {
+	case shared_utils.FilterNot:
+		if len(conditions) != 1 {
+			return "", nil, fmt.Errorf("%w: NOT takes exactly one condition", shared_dto.ErrInvalidQuery)
+		}
+		return fmt.Sprintf("NOT (%s)", conditions[0]), values, nil
+	case shared_utils.FilterAnd, shared_utils.FilterOr:
+		if len(conditions) == 0 {
+			return "", nil, fmt.Errorf("%w: empty %s group", shared_dto.ErrInvalidQuery, node.Op)
+		}
+		return "(" + strings.Join(conditions, " "+node.Op+" ") + ")", values, nil
+	}
+	return "", nil, fmt.Errorf("%w: unknown filter operator %s", shared_dto.ErrInvalidQuery, node.Op)
+}
+
+func buildSearchCondition(schema *QuerySchema, query shared_utils.SearchQuery) (string, []interface{}, error) {
+	field, err := schema.Resolve(query.Field)
+	if err != nil {
+		return "", nil, err
+	}
+	column := field.SQL()
+
+	if field.IsMultiValued() && scalarOperators[query.Operator] {
+		return "", nil, fmt.Errorf("%w: %s does not apply to multi-valued properties, use contains, hasany or hasall on %s", shared_dto.ErrInvalidQuery, query.Operator, query.Field)
+	}
+
+	switch query.Operator {
+	case "equal", "notequal":
+		value, err := searchValue(field, query.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		if query.Operator == "notequal" {
+			// Rows without a value are different from any value.
+			return fmt.Sprintf("%s IS DISTINCT FROM ?", column), []interface{}{value}, nil
+		}
+		return fmt.Sprintf("%s = ?", column), []interface{}{value}, nil
+	case "include":
+		return fmt.Sprintf("%s::text ILIKE ?", column), []interface{}{"%" + EscapeLike(strings.TrimSpace(query.Value)) + "%"}, nil
+	case "startswith", "endswith", "like", "regex":
+		if !field.IsText() {
+			return "", nil, fmt.Errorf("%w: %s only applies to text properties, %s is %s", shared_dto.ErrInvalidQuery, query.Operator, query.Field, field.SQLType)
+		}
+		switch query.Operator {
+		case "startswith":
+			return fmt.Sprintf("%s ILIKE ?", column), []interface{}{EscapeLike(query.Value) + "%"}, nil
+		case "endswith":
+			return fmt.Sprintf("%s ILIKE ?", column), []interface{}{"%" + EscapeLike(query.Value)}, nil
+		case "like":
+			return fmt.Sprintf("%s LIKE ?", column), []interface{}{query.Value}, nil
+		}
+		return fmt.Sprintf("%s ~ ?", column), []interface{}{query.Value}, nil
+	case "in", "notin":
+		queryValue := strings.Split(query.Value, ",")
+		interfaceValues := make([]interface{}, len(queryValue))
+		for i, v := range queryValue {
+			if interfaceValues[i], err = searchValue(field, v); err != nil {
+				return "", nil, err
+			}
+		}
+		if query.Operator == "notin" {
+			return fmt.Sprintf("(%s IS NULL OR %s NOT IN ?)", column, column), []interface{}{interfaceValues}, nil
+		}
+		return fmt.Sprintf("%s IN ?", column), []interface{}{interfaceValues}, nil
+	case "from", "to":
+		value, err := searchValue(field, strings.TrimSpace(query.Value))
+		if err != nil {
+			return "", nil, err
+		}
+		op := ">="
+		if query.Operator == "to" {
+			op = "<="
+		}
+		return fmt.Sprintf("%s %s ?", column, op), []interface{}{value}, nil
+	case "fromto":
+		fromTo := strings.Split(query.Value, ",")
+		from, err := searchValue(field, strings.TrimSpace(fromTo[0]))
+		if err != nil {
+			return "", nil, err
+		}
+		to, err := searchValue(field, strings.TrimSpace(fromTo[1]))
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s BETWEEN ? AND ?", column), []interface{}{from, to}, nil
+	case "isnull":
+		return fmt.Sprintf("%s IS NULL", column), nil, nil
+	case "notnull":
+		return fmt.Sprintf("%s IS NOT NULL", column), nil, nil
+	case "contains":
+		if !field.IsMultiValued() {
+			return "", nil, fmt.Errorf("%w: contains only applies to multi-valued properties, %s is %s", shared_dto.ErrInvalidQuery, query.Field, field.SQLType)
+		}
+		return fmt.Sprintf("? = ANY(%s)", column), []interface{}{strings.TrimSpace(query.Value)}, nil
+	case "hasany", "hasall":
+		if !field.IsMultiValued() {
+			return "", nil, fmt.Errorf("%w: %s only applies to multi-valued properties, %s is %s", shared_dto.ErrInvalidQuery, query.Operator, query.Field, field.SQLType)
+		}
+		var values value_type.TextArray
+		for _, v := range strings.Split(query.Value, ",") {
+			if v = strings.TrimSpace(v); len(v) > 0 {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return "", nil, fmt.Errorf("%w: %s needs at least one value", shared_dto.ErrInvalidQuery, query.Operator)
+		}
+		op := "&&"
+		if query.Operator == "hasall" {
+			op = "@>"
+		}
+		return fmt.Sprintf("%s %s ?", column, op), []interface{}{values}, nil
+	}
+	return "", nil, fmt.Errorf("%w: unknown operator %s", shared_dto.ErrInvalidQuery, query.Operator)
+}
+
+// scalarOperators compare a single value and cannot be applied to array columns.
+var scalarOperators = map[string]bool{
+	"equal": true, "notequal": true, "in": true, "notin": true, "from": true, "to": true, "fromto": true,
+}
+
+// EscapeLike escapes the LIKE wildcards of value so that it is matched literally.
+func EscapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// searchValue converts a filter value to the value type of the property it is compared with.
+// Dates and date-times also accept a time relative to now such as `now-7d`.
+func searchValue(field QueryField, value string) (interface{}, error) {
+	vt := field.ValueType()
+	var raw interface{} = value
+	if vt == value_type.Date || vt == value_type.DateTime || field.SQLType == "timestamptz" {
+		if t, ok := value_type.ParseRelativeTime(value, time.Now()); ok {
+			raw = t
+		}
+	}
+	if len(vt) == 0 {
+		return raw, nil
+	}
+	result, err := value_type.Coerce(vt, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", shared_dto.ErrInvalidQuery, field.Column, err)
+	}
+	return result, nil
 }
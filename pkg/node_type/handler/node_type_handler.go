@@ -1,12 +1,15 @@
 package node_type_handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/iancoleman/strcase"
 	"github.com/ledaian41/go-cms-service/pkg/node_type/utils"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
 	"github.com/ledaian41/go-cms-service/pkg/shared/interface"
 	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
 )
@@ -19,6 +22,39 @@ func NewNodeTypeHandler(nodeTypeService shared_interface.NodeTypeService) *NodeT
 	return &NodeType{nodeTypeService: nodeTypeService}
 }
 
+func respondError(c *gin.Context, err error) {
+	var validationErr *shared_dto.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "validation failed", "fields": validationErr.Fields})
+		return
+	}
+	if errors.Is(err, shared_dto.ErrRecordReferenced) || errors.Is(err, shared_dto.ErrReferenceDeleted) ||
+		errors.Is(err, shared_dto.ErrSlugTaken) {
+		c.String(http.StatusConflict, err.Error())
+		return
+	}
+	if errors.Is(err, ErrUnsupportedContentType) {
+		c.String(http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+	c.String(http.StatusBadRequest, err.Error())
+}
+
+// bindRecordData reads the request body, converts every value to the type declared by the node type
+// and stores uploaded files, returning the data ready to be written.
+func (n *NodeType) bindRecordData(c *gin.Context, nodeType shared_dto.NodeTypeDTO) (map[string]interface{}, error) {
+	rawData, err := readRequestData(c)
+	if err != nil {
+		return nil, err
+	}
+
+	coercedData, err := n.nodeTypeService.CoerceRecord(nodeType, rawData)
+	if err != nil {
+		return nil, err
+	}
+	return n.nodeTypeService.PreprocessFile(nodeType, coercedData)
+}
+
 func (n *NodeType) ReadNodeTypeInfo(c *gin.Context) {
 	typeId := strcase.ToLowerCamel(c.Param("typeId"))
 	nodeType := n.nodeTypeService.FetchNodeType(typeId)
@@ -31,76 +67,233 @@ func (n *NodeType) ReadNodeTypeInfo(c *gin.Context) {
 // @Description \n
 // @Description **Filtering syntax** (all remaining URL query params are interpreted as filters):
 // @Description - Pattern: `{field}_{operator}={value}`
-// @Description - Supported operators: `equal`, `include`, `in`, `from`, `to`, `fromto`
+// @Description - Supported operators: `equal`, `notequal`, `include`, `in`, `notin`, `from`, `to`, `fromto`, `isnull`, `notnull`,
+// @Description   `startswith`, `endswith`, `like`, `regex`, `contains`
 // @Description - Semantics:
 // @Description   * `equal`: exact match (e.g. `status_equal=published`)
-// @Description   * `include`: substring/contains (e.g. `title_include=hello`)
+// @Description   * `notequal`: different value, rows without a value match too (e.g. `status_notequal=draft`)
+// @Description   * `include`: substring/contains, case-insensitive (e.g. `title_include=hello`)
 // @Description   * `in`: membership list, comma-separated (e.g. `type_in=article,page`)
+// @Description   * `notin`: not in the comma-separated list, rows without a value match too
 // @Description   * `from`: lower bound (>=), typically for dates/numbers (e.g. `createdAt_from=2025-01-01T00:00:00Z`)
 // @Description   * `to`: upper bound (<=) (e.g. `createdAt_to=2025-12-31T23:59:59Z`)
 // @Description   * `fromto`: range (e.g. `price_fromto=10,100`)
+// @Description   * dates also accept a time relative to now in `s`, `m`, `h`, `d`, `w`, `M` or `y` (e.g. `createdAt_from=now-7d`)
+// @Description   * `isnull` / `notnull`: property has no value / has a value, the value is ignored (e.g. `image_isnull=1`)
+// @Description   * `startswith` / `endswith`: case-insensitive prefix / suffix of text properties (e.g. `sku_startswith=SHO-`)
+// @Description   * `like`: case-sensitive SQL LIKE pattern on text properties, `%` and `_` are wildcards (e.g. `title_like=Go%25`)
+// @Description   * `regex`: POSIX regular expression on text properties (e.g. `sku_regex=^[A-Z]{3}-[0-9]+$`)
+// @Description   * `contains`: multi-valued property (`REFERENCES`) holds the value (e.g. `tags_contains=go`)
+// @Description   * `hasany`, `hasall`: multi-valued property holds any / all of the comma-separated values (e.g. `tags_hasany=go,rust`)
 // @Description - Examples: `GET /{typeId}?title_include=guide&status_in=draft,published&createdAt_from=2025-01-01T00:00:00Z`
+// @Description - Fields are properties of the node type (camelCase or snake_case), system columns (`id`, `createdAt`, ...)
+// @Description   or `<reference>.<field>` (also `<reference>.<nested>.<field>`) for fields of referenced nodes, which are
+// @Description   joined automatically. Unknown fields and values that do not
+// @Description   match the value type of the property return 400.
+// @Description \n
+// @Description **Filter expressions** (`filter` param) combine the same terms with `AND`, `OR`, `NOT` and parentheses:
+// @Description - Term: `{field}_{operator}:{value}`, double-quote values containing spaces or parentheses
+// @Description - `AND` binds tighter than `OR`, keywords are case-insensitive
+// @Description - Example: `filter=(status_equal:draft OR author_equal:me) AND NOT title_include:"hello world"`
+// @Description - The expression is ANDed with the `{field}_{operator}` params
 // @Description \n
 // @Description **Sorting syntax**
 // @Description - Pattern: `<field> <asc|desc>`; default direction is `asc` if omitted (e.g., `createdAt` == `createdAt asc`)
 // @Description - Multiple fields: separate by comma, evaluated left-to-right (e.g., `name desc,age asc`)
 // @Description - URL encoding: encode spaces as `%20` or `+` (e.g., `name%20desc,age%20asc`)
 // @Description - Examples: `GET /{typeId}?sort=createdAt%20desc,id`, `GET /{typeId}?sort=name%20desc,age%20asc`
+// @Description - Sort fields are resolved like filter fields, any direction other than `asc`/`desc` returns 400.
+// @Description - Rows without a value come last in both directions and `id` is always the final tie-breaker.
+// @Description \n
+// @Description **Pagination**: `page`/`pageSize` (offset) or `cursor` (keyset). Every page with more rows returns
+// @Description `hasNext: true` and a `nextCursor`; pass it as `cursor` with the same `sort` to get the following page.
 // @Tags NodeType
 // @Accept json
 // @Produce json
 // @Param typeId path string true "Type ID"
 // @Param page query int false "Page number (1-based)" default(1) minimum(1)
 // @Param pageSize query int false "Items per page (1-1000)" default(10) minimum(1) maximum(1000)
+// @Param cursor query string false "Keyset pagination: send an empty `cursor` for the first page, then the `nextCursor` of the previous page. `page` is ignored"
+// @Param count query bool false "Set to false to skip counting `total` and `totalPage`" default(true)
 // @Param sort query string false "Sort expression: `<field> <asc|desc>`, multiple fields separated by comma. Example: `name desc,age asc`. Default direction is `asc` if omitted. Use `%20` (or `+`) to encode spaces in URLs: `name%20desc,age%20asc`"
-// @Param filter query string false "Dynamic filters: `{field}_{operator}={value}`. Operators: `equal|include|in|from|to|fromto`. Example: `name_equal=ABC&age_from=20`"
-// @Param referenceView query string false "true or field name to fetch related records"
-// @Success 200 {object} map[string]interface{} "{ items: [...], pagination: { page, pageSize, total, hasNext, nextCursor? } }"
+// @Param filter query string false "Filter expression: `{field}_{operator}:{value}` terms combined with `AND`, `OR`, `NOT` and parentheses. Example: `(status_equal:draft OR author_equal:me) AND NOT price_from:100`"
+// @Param q query string false "Full-text search over the searchable properties, with web search syntax (phrases, or, -excluded). Results are ordered by rank unless `sort` is given"
+// @Param highlight query bool false "With `q`, adds the matching snippets under `_highlight`" default(false)
+// @Param referenceView query string false "Comma-separated reference paths to expand, e.g. category.parent,tags"
+// @Param fields query string false "Comma-separated fields to return, `<reference>.<field>` limits the columns of a joined reference and joins it. Example: `name,price,category.name`"
+// @Param format query string false "Representation of RICH_TEXT properties: sanitised `html`, the `markdown` source or plain `text`" Enums(html, markdown, text) default(html)
+// @Success 200 {object} map[string]interface{} "{ items: [...], pagination: { page?, pageSize, total?, totalPage?, hasNext, nextCursor? } }"
 // @Failure 400 {string} string "bad request"
 // @Router /{typeId} [get]
 func (n *NodeType) ListApi(c *gin.Context) {
 	typeId := strcase.ToSnake(c.Param("typeId"))
-	records, pagination, err := n.nodeTypeService.FetchRecords(typeId, shared_utils.QueryOption{
+	records, pagination, err := n.nodeTypeService.FetchRecords(typeId, listQueryOption(c, typeId))
+	cleanedRecords := n.cleanRecords(records)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":      cleanedRecords,
+		"pagination": pagination,
+	})
+}
+
+// listQueryOption reads the paging, sorting, filtering and projection params of list endpoints.
+func listQueryOption(c *gin.Context, typeId string) shared_utils.QueryOption {
+	cursor, useCursor := c.GetQuery("cursor")
+	return shared_utils.QueryOption{
 		TypeId:        typeId,
 		ReferenceView: c.Query("referenceView"),
 		PageSize:      int8(shared_utils.ParseInt(c.Query("pageSize"))),
 		Page:          int32(shared_utils.ParseInt(c.Query("page"))),
 		SortBy:        c.Query("sort"),
+		Filter:        c.Query("filter"),
+		Fields:        c.Query("fields"),
+		UseCursor:     useCursor,
+		Cursor:        cursor,
+		SkipCount:     c.Query("count") == "false",
+		Search:        strings.TrimSpace(c.Query("q")),
+		Highlight:     c.Query("highlight") == "true",
+		Format:        c.Query("format"),
 		Query:         c.Request.URL.Query(),
-	})
+	}
+}
+
+func (n *NodeType) cleanRecords(records []map[string]interface{}) []interface{} {
 	cleanedRecords := make([]interface{}, 0)
 	for _, record := range records {
 		cleaned := nodeType_utils.OmitEmpty(record)
 		n.nodeTypeService.ProcessFilePath(cleaned)
 		cleanedRecords = append(cleanedRecords, cleaned)
 	}
+	return cleanedRecords
+}
+
+// RelatedApi godoc
+// @Summary List related nodes
+// @Description List the nodes of `relatedTypeId` whose `REFERENCE` or `REFERENCES` property points at the node,
+// @Description e.g. `GET /productCategory/{id}/related/product?via=category`. `via` names the property and can be
+// @Description left out when `relatedTypeId` references the type through a single property.
+// @Description Filtering, sorting, pagination and projection work as on the list endpoint of `relatedTypeId`.
+// @Tags NodeType
+// @Accept json
+// @Produce json
+// @Param typeId path string true "Type ID"
+// @Param id path string true "Node ID or slug"
+// @Param relatedTypeId path string true "Type ID of the referencing nodes"
+// @Param via query string false "Property of relatedTypeId referencing typeId"
+// @Param page query int false "Page number (1-based)" default(1) minimum(1)
+// @Param pageSize query int false "Items per page" default(10) minimum(1) maximum(100)
+// @Param cursor query string false "Keyset pagination cursor, see the list endpoint"
+// @Param count query bool false "Set to false to skip counting `total` and `totalPage`" default(true)
+// @Param sort query string false "Sort expression, see the list endpoint"
+// @Param filter query string false "Filter expression, see the list endpoint"
+// @Param referenceView query string false "Comma-separated reference paths to expand"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} map[string]interface{} "{ items: [...], pagination: {...} }"
+// @Failure 400 {string} string "bad request"
+// @Failure 404 {string} string "node or related type not found"
+// @Router /{typeId}/{id}/related/{relatedTypeId} [get]
+func (n *NodeType) RelatedApi(c *gin.Context) {
+	typeId := c.Param("typeId")
+	key := c.Param("id")
+	relatedTypeId := strcase.ToSnake(c.Param("relatedTypeId"))
+
+	id, err := n.nodeTypeService.ResolveRecordID(typeId, key)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	record, err := n.nodeTypeService.FetchRecord(typeId, id, shared_utils.QueryOption{})
+	if err != nil || record == nil {
+		c.String(http.StatusNotFound, fmt.Sprintf("%s::%s not found", typeId, key))
+		return
+	}
+
+	records, pagination, err := n.nodeTypeService.FetchRelatedRecords(typeId, id, relatedTypeId, c.Query("via"), listQueryOption(c, relatedTypeId))
+	if errors.Is(err, shared_dto.ErrNodeTypeNotFound) {
+		c.String(http.StatusNotFound, err.Error())
+		return
+	}
 	if err != nil {
 		c.String(http.StatusBadRequest, err.Error())
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"items":      cleanedRecords,
+		"items":      n.cleanRecords(records),
 		"pagination": pagination,
 	})
 }
 
+// AggregateApi godoc
+// @Summary Aggregate nodes by type
+// @Description Count the nodes matching the filters of the list endpoint per group of `groupBy`, with the `sum`, `avg`,
+// @Description `min` and `max` of numeric properties, e.g. `GET /aggregate/product?groupBy=category&sum=price&avg=price`.
+// @Description A `REFERENCE` group is returned as `{ id, label }` where the label is its `referenceValue` property.
+// @Description Without `groupBy` a single item covers all matching nodes. Groups are ordered by count, largest first.
+// @Tags NodeType
+// @Produce json
+// @Param typeId path string true "Type ID"
+// @Param groupBy query string false "Comma-separated fields to group by, `<reference>.<field>` groups by a field of a referenced node"
+// @Param sum query string false "Comma-separated numeric fields to sum"
+// @Param avg query string false "Comma-separated numeric fields to average"
+// @Param min query string false "Comma-separated numeric fields to take the minimum of"
+// @Param max query string false "Comma-separated numeric fields to take the maximum of"
+// @Param filter query string false "Filter expression, see the list endpoint"
+// @Param q query string false "Full-text search, see the list endpoint"
+// @Success 200 {object} map[string]interface{} "{ items: [{ group: {...}, count: n, sum: {...}, avg: {...} }] }"
+// @Failure 400 {string} string "bad request"
+// @Router /aggregate/{typeId} [get]
+func (n *NodeType) AggregateApi(c *gin.Context) {
+	typeId := strcase.ToSnake(c.Param("typeId"))
+	aggregate := shared_utils.AggregateOption{GroupBy: c.Query("groupBy"), Metrics: make(map[string]string)}
+	for _, function := range shared_utils.AggregateFunctions {
+		aggregate.Metrics[function] = c.Query(function)
+	}
+
+	items, err := n.nodeTypeService.AggregateRecords(typeId, listQueryOption(c, typeId), aggregate)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
 // ReadApi godoc
 // @Summary	Get node details
-// @Description Get detailed information of a specific node
+// @Description Get detailed information of a specific node, designated by its id or, when the type has a `SLUG`
+// @Description property, by its slug (e.g. `GET /product/soft-cotton-tee`). An id takes precedence over a slug.
 // @Tags	NodeType
 // @Accept	json
 // @Produce json
 // @Param typeId path string true "Type ID"
-// @Param id path string true "Node ID"
+// @Param id path string true "Node ID or slug"
+// @Param referenceView query string false "Comma-separated reference paths to expand, e.g. category.parent,tags"
+// @Param fields query string false "Comma-separated fields to return, `<reference>.<field>` limits the columns of a joined reference. Example: `name,price,category.name`"
+// @Param format query string false "Representation of RICH_TEXT properties: sanitised `html`, the `markdown` source or plain `text`. An HTML source is returned for `markdown`" Enums(html, markdown, text) default(html)
+// @Param includeReverse query string false "Nodes referencing this node, listed under `_related` by `<typeId>.<property>`: `true` for every reference, or comma-separated `<typeId>` / `<typeId>.<property>`. Example: `product.category`"
 // @Success 200
 // @Failure 400
 // @Failure 404
 // @Router /{typeId}/{id} [get]
 func (n *NodeType) ReadApi(c *gin.Context) {
 	typeId := c.Param("typeId")
-	id := c.Param("id")
-	result, err := n.nodeTypeService.FetchRecord(typeId, c.Param("id"))
+	key := c.Param("id")
+	id, err := n.nodeTypeService.ResolveRecordID(typeId, key)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	result, err := n.nodeTypeService.FetchRecord(typeId, id, shared_utils.QueryOption{
+		TypeId:        typeId,
+		ReferenceView: c.Query("referenceView"),
+		Fields:        c.Query("fields"),
+		Format:        c.Query("format"),
+	})
 	result = nodeType_utils.OmitEmpty(result)
 	n.nodeTypeService.ProcessFilePath(result)
 	if err != nil {
@@ -108,55 +301,56 @@ func (n *NodeType) ReadApi(c *gin.Context) {
 		return
 	}
 	if result == nil {
-		c.String(http.StatusNotFound, fmt.Sprintf("%s::%s not found", typeId, id))
+		c.String(http.StatusNotFound, fmt.Sprintf("%s::%s not found", typeId, key))
 		return
 	}
+	if includeReverse := c.Query("includeReverse"); len(includeReverse) > 0 {
+		relations, err := n.nodeTypeService.FetchReverseRelations(typeId, id, includeReverse)
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		related := make(map[string]interface{}, len(relations))
+		for key, records := range relations {
+			related[key] = n.cleanRecords(records)
+		}
+		result["_related"] = related
+	}
 	c.JSON(http.StatusOK, result)
 }
 
 // CreateApi godoc
 // @Summary Create new node
-// @Description Create a new node with form data
+// @Description Create a new node from a JSON object or multipart form data.
+// @Description - `application/json`: values keep their native types (numbers, booleans, `null`, arrays).
+// @Description - `multipart/form-data`: one field per property, FILE properties are uploaded as files.
+// @Description   A `_json` part holding a JSON object may be sent alongside, its keys are merged with the form fields.
 // @Tags NodeType
-// @Accept multipart/form-data
+// @Accept json,mpfd
 // @Produce json
 // @Param typeId path string true "Type ID"
 // @Param title formData string true "Node title"
 // @Param content formData string false "Node content"
 // @Param image formData file false "Image file"
+// @Param _json formData string false "JSON object merged with the form fields"
 // @Success 200
 // @Failure 400
+// @Failure 415 {string} string "unsupported content type"
+// @Failure 409 {string} string "concurrent writes kept taking the slug"
+// @Failure 422 {object} map[string]interface{} "{ error: \"validation failed\", fields: [{ field, rule, message }] }"
 // @Router /{typeId} [post]
 func (n *NodeType) CreateApi(c *gin.Context) {
 	typeId := c.Param("typeId")
 
-	rawData := make(map[string]interface{})
-	form, err := c.MultipartForm()
+	parsedData, err := n.bindRecordData(c, n.nodeTypeService.FetchNodeType(typeId))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	for key, values := range form.Value {
-		if len(values) > 0 {
-			rawData[key] = values[0]
-		}
-	}
-	for key, files := range form.File {
-		if len(files) > 0 {
-			rawData[key] = files[0]
-		}
-	}
-
-	parsedData, err := n.nodeTypeService.PreprocessFile(n.nodeTypeService.FetchNodeType(typeId), rawData)
-	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	newNode, err := n.nodeTypeService.CreateRecord(typeId, parsedData)
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -165,56 +359,44 @@ func (n *NodeType) CreateApi(c *gin.Context) {
 
 // UpdateApi godoc
 // @Summary Update existing node
-// @Description Update node information
+// @Description Update node information from a JSON object or multipart form data.
+// @Description Only the properties sent are changed, an explicit JSON `null` clears the property.
+// @Description A `_json` part holding a JSON object may be sent alongside multipart form fields.
 // @Tags NodeType
-// @Accept multipart/form-data
+// @Accept json,mpfd
 // @Produce json
 // @Param typeId path string true "Type ID"
 // @Param id path string true "Node ID"
 // @Param title formData string false "Node title"
 // @Param content formData string false "Node content"
 // @Param image formData file false "Image file"
+// @Param _json formData string false "JSON object merged with the form fields"
 // @Success 200
 // @Failure 400
 // @Failure 404
+// @Failure 415 {string} string "unsupported content type"
+// @Failure 409 {string} string "concurrent writes kept taking the slug"
+// @Failure 422 {object} map[string]interface{} "{ error: \"validation failed\", fields: [{ field, rule, message }] }"
 // @Router /{typeId}/{id} [put]
 func (n *NodeType) UpdateApi(c *gin.Context) {
 	typeId := c.Param("typeId")
 	id := c.Param("id")
 
-	rawData := make(map[string]interface{})
-	form, err := c.MultipartForm()
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	for key, values := range form.Value {
-		if len(values) > 0 {
-			rawData[key] = values[0]
-		}
-	}
-	for key, files := range form.File {
-		if len(files) > 0 {
-			rawData[key] = files[0]
-		}
-	}
-
-	record, err := n.nodeTypeService.FetchRecord(typeId, id)
+	record, err := n.nodeTypeService.FetchRecord(typeId, id, shared_utils.QueryOption{})
 	if err != nil || record == nil {
 		c.String(http.StatusNotFound, fmt.Sprintf("%s::%s not found", typeId, id))
 		return
 	}
 
-	parsedData, err := n.nodeTypeService.PreprocessFile(n.nodeTypeService.FetchNodeType(typeId), rawData)
+	parsedData, err := n.bindRecordData(c, n.nodeTypeService.FetchNodeType(typeId))
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	updateNode, err := n.nodeTypeService.UpdateRecord(typeId, id, parsedData)
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, updateNode)
@@ -222,7 +404,9 @@ func (n *NodeType) UpdateApi(c *gin.Context) {
 
 // DeleteApi godoc
 // @Summary Soft delete node
-// @Description Soft delete (mark as deleted) a specific node by setting deleted_at/deleted_by
+// @Description Soft delete (mark as deleted) a specific node by setting deleted_at/deleted_by.
+// @Description References to it declaring `onDelete` are applied: `restrict` blocks the delete, `cascade` deletes the
+// @Description referencing nodes and `setNull` clears the reference.
 // @Tags NodeType
 // @Accept json
 // @Produce json
@@ -231,18 +415,19 @@ func (n *NodeType) UpdateApi(c *gin.Context) {
 // @Success 200
 // @Failure 400
 // @Failure 404
+// @Failure 409 {string} string "a restrict reference blocks the delete"
 // @Router /{typeId}/{id} [delete]
 func (n *NodeType) DeleteApi(c *gin.Context) {
 	typeId := c.Param("typeId")
 	id := c.Param("id")
-	record, err := n.nodeTypeService.FetchRecord(typeId, id)
+	record, err := n.nodeTypeService.FetchRecord(typeId, id, shared_utils.QueryOption{})
 	if err != nil || record == nil {
 		c.String(http.StatusNotFound, fmt.Sprintf("%s::%s not found", typeId, id))
 		return
 	}
 
 	if err = n.nodeTypeService.DeleteRecord(typeId, id); err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "success"})
@@ -251,6 +436,7 @@ func (n *NodeType) DeleteApi(c *gin.Context) {
 // RestoreApi godoc
 // @Summary Restore node
 // @Description Soft-restore a previously soft-deleted node by clearing `deleted_at` and `deleted_by`.
+// @Description Nodes deleted together with it through `onDelete: cascade` are restored as well.
 // @Tags NodeType
 // @Accept json
 // @Produce json
@@ -259,11 +445,16 @@ func (n *NodeType) DeleteApi(c *gin.Context) {
 // @Success 200 {object} map[string]string "{ message: \"success\" }"
 // @Failure 404 {object} map[string]string "{ error: \"record not found or not deleted\" }"
 // @Failure 400 {string} string "bad request"
+// @Failure 409 {string} string "the node references a deleted node"
 // @Router /{typeId}/{id}/restore [post]
 func (n *NodeType) RestoreApi(c *gin.Context) {
 	typeId := c.Param("typeId")
 	id := c.Param("id")
 	err := n.nodeTypeService.RestoreRecord(typeId, id)
+	if errors.Is(err, shared_dto.ErrReferenceDeleted) {
+		respondError(c, err)
+		return
+	}
 	if err != nil {
 		c.String(http.StatusNotFound, fmt.Sprintf("%s::%s not found or not deleted", typeId, id))
 		return
@@ -1,13 +1,17 @@
 package node_type_handler
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ledaian41/go-cms-service/pkg/node_type/service"
 	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
 	shared_utils "github.com/ledaian41/go-cms-service/pkg/shared/utils"
 	"github.com/stretchr/testify/assert"
@@ -24,7 +28,14 @@ func (m *MockNodeTypeService) FetchNodeTypes() *[]shared_dto.NodeTypeDTO {
 }
 
 func (m *MockNodeTypeService) FetchNodeType(tid string) shared_dto.NodeTypeDTO {
-	panic("implement me")
+	return shared_dto.NodeTypeDTO{
+		TID: tid,
+		PropertyTypes: []shared_dto.PropertyTypeDTO{
+			{PID: "name", ValueType: "STRING"},
+			{PID: "price", ValueType: "DOUBLE"},
+			{PID: "image", ValueType: "FILE"},
+		},
+	}
 }
 
 func (m *MockNodeTypeService) LoadSchema(filePath string, ch chan<- string) {
@@ -51,7 +62,6 @@ func (m *MockNodeTypeService) FetchPropertyTypesByTid(tid string) []shared_dto.P
 }
 
 func (m *MockNodeTypeService) ProcessFilePath(record map[string]interface{}) {
-	panic("implement me")
 }
 
 func (m *MockNodeTypeService) FetchRecords(tid string, option shared_utils.QueryOption) ([]map[string]interface{}, *shared_dto.PaginationDTO, error) {
@@ -63,7 +73,7 @@ func (m *MockNodeTypeService) FetchRecords(tid string, option shared_utils.Query
 	return args.Get(0).([]map[string]interface{}), nil, args.Error(1)
 }
 
-func (m *MockNodeTypeService) FetchRecord(tid string, id string) (map[string]interface{}, error) {
+func (m *MockNodeTypeService) FetchRecord(tid string, id string, option shared_utils.QueryOption) (map[string]interface{}, error) {
 	args := m.Called(tid, id)
 	if args.Get(0) == nil {
 		if args.Get(1) == nil {
@@ -74,6 +84,35 @@ func (m *MockNodeTypeService) FetchRecord(tid string, id string) (map[string]int
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
+func (m *MockNodeTypeService) ResolveRecordID(tid string, key string) (string, error) {
+	args := m.Called(tid, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockNodeTypeService) AggregateRecords(tid string, option shared_utils.QueryOption, aggregate shared_utils.AggregateOption) ([]map[string]interface{}, error) {
+	args := m.Called(tid, aggregate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+
+func (m *MockNodeTypeService) FetchRelatedRecords(tid, id, relatedTid, via string, option shared_utils.QueryOption) ([]map[string]interface{}, *shared_dto.PaginationDTO, error) {
+	args := m.Called(tid, id, relatedTid, via)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), &shared_dto.PaginationDTO{}, args.Error(1)
+}
+
+func (m *MockNodeTypeService) FetchReverseRelations(tid, id, spec string) (map[string][]map[string]interface{}, error) {
+	args := m.Called(tid, id, spec)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]map[string]interface{}), args.Error(1)
+}
+
 func (m *MockNodeTypeService) CreateRecord(tid string, data map[string]interface{}) (map[string]interface{}, error) {
 	args := m.Called(tid, data)
 	if args.Get(0) == nil {
@@ -88,12 +127,16 @@ func (m *MockNodeTypeService) UpdateRecord(tid string, id string, data map[strin
 }
 
 func (m *MockNodeTypeService) DeleteRecord(tid string, id string) error {
-	//TODO implement me
-	panic("implement me")
+	args := m.Called(tid, id)
+	return args.Error(0)
+}
+
+func (m *MockNodeTypeService) CoerceRecord(nodeTypeDTO shared_dto.NodeTypeDTO, rawData map[string]interface{}) (map[string]interface{}, error) {
+	return node_type_service.CoerceRecord(nodeTypeDTO.PropertyTypes, rawData)
 }
 
 func (m *MockNodeTypeService) PreprocessFile(nodeTypeDTO shared_dto.NodeTypeDTO, rawData map[string]interface{}) (map[string]interface{}, error) {
-	panic("implement me")
+	return rawData, nil
 }
 
 func TestListApi_Success(t *testing.T) {
@@ -104,7 +147,7 @@ func TestListApi_Success(t *testing.T) {
 		{"id": 1, "name": "product A", "price": 200000},
 		{"id": 2, "name": "product B", "price": 400000},
 	}
-	mockService.On("FetchRecords", "product").Return(&mockData, nil)
+	mockService.On("FetchRecords", "product").Return(mockData, nil)
 
 	handler := NewNodeTypeHandler(mockService)
 
@@ -119,7 +162,7 @@ func TestListApi_Success(t *testing.T) {
 	handler.ListApi(c)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	expectedResponse := `[{"id":1,"name":"product A","price":200000},{"id":2,"name":"product B","price":400000}]`
+	expectedResponse := `{"items":[{"id":1,"name":"product A","price":200000},{"id":2,"name":"product B","price":400000}],"pagination":null}`
 	assert.JSONEq(t, expectedResponse, w.Body.String())
 
 	mockService.AssertExpectations(t)
@@ -154,10 +197,11 @@ func TestReadApi_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := new(MockNodeTypeService)
+	mockService.On("ResolveRecordID", "product", "1").Return("1", nil)
 	mockData := map[string]interface{}{
 		"id": 1, "name": "product A", "price": 200000,
 	}
-	mockService.On("FetchRecord", "product", "1").Return(&mockData, nil)
+	mockService.On("FetchRecord", "product", "1").Return(mockData, nil)
 
 	handler := NewNodeTypeHandler(mockService)
 
@@ -179,10 +223,37 @@ func TestReadApi_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestReadApi_Slug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockNodeTypeService)
+	mockService.On("ResolveRecordID", "product", "soft-cotton-tee").Return("1", nil)
+	mockService.On("FetchRecord", "product", "1").Return(map[string]interface{}{"id": "1", "slug": "soft-cotton-tee"}, nil)
+
+	handler := NewNodeTypeHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, "/product/soft-cotton-tee", nil)
+	c.Params = gin.Params{
+		{Key: "typeId", Value: "product"},
+		{Key: "id", Value: "soft-cotton-tee"},
+	}
+
+	handler.ReadApi(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"1","slug":"soft-cotton-tee"}`, w.Body.String())
+
+	mockService.AssertExpectations(t)
+}
+
 func TestReadApi_BadRequest(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := new(MockNodeTypeService)
+	mockService.On("ResolveRecordID", "product", "1").Return("1", nil)
 	mockService.On("FetchRecord", "product", "1").Return(nil, errors.New("db error"))
 
 	handler := NewNodeTypeHandler(mockService)
@@ -208,6 +279,7 @@ func TestReadApi_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := new(MockNodeTypeService)
+	mockService.On("ResolveRecordID", "product", "1").Return("1", nil)
 	mockService.On("FetchRecord", "product", "1").Return(nil, nil)
 
 	handler := NewNodeTypeHandler(mockService)
@@ -224,7 +296,7 @@ func TestReadApi_NotFound(t *testing.T) {
 	handler.ReadApi(c)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
-	assert.Equal(t, w.Body.String(), "not found")
+	assert.Equal(t, "product::1 not found", w.Body.String())
 
 	mockService.AssertExpectations(t)
 }
@@ -238,7 +310,7 @@ func TestCreateApi_Success(t *testing.T) {
 	requestData := map[string]interface{}{"name": "New Product", "price": float64(300000)}
 	createdData := map[string]interface{}{"id": 1, "name": "New Product", "price": 300000}
 
-	mockService.On("CreateRecord", "product", requestData).Return(&createdData, nil)
+	mockService.On("CreateRecord", "product", requestData).Return(createdData, nil)
 
 	handler := NewNodeTypeHandler(mockService)
 
@@ -312,8 +384,8 @@ func TestUpdateApi_Success(t *testing.T) {
 	requestChangeData := map[string]interface{}{"name": "Updated Product", "price": float64(300000)}
 	mockCurrentData := map[string]interface{}{"id": 1, "name": "New Product", "price": 200000}
 	updatedData := map[string]interface{}{"id": 1, "name": "Updated Product", "price": 300000}
-	mockService.On("FetchRecord", "product", "1").Return(&mockCurrentData, nil)
-	mockService.On("UpdateRecord", "product", "1", requestChangeData).Return(&updatedData, nil)
+	mockService.On("FetchRecord", "product", "1").Return(mockCurrentData, nil)
+	mockService.On("UpdateRecord", "product", "1", requestChangeData).Return(updatedData, nil)
 
 	handler := NewNodeTypeHandler(mockService)
 
@@ -328,3 +400,230 @@ func TestUpdateApi_Success(t *testing.T) {
 	expectedJSON := `{"id":1,"name":"Updated Product","price":300000}`
 	assert.JSONEq(t, expectedJSON, w.Body.String())
 }
+
+func TestUpdateApi_JSONNullClearsField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockNodeTypeService)
+	requestBody := `{"price": null}`
+	requestChangeData := map[string]interface{}{"price": nil}
+	mockService.On("FetchRecord", "product", "1").Return(map[string]interface{}{"id": 1}, nil)
+	mockService.On("UpdateRecord", "product", "1", requestChangeData).Return(requestChangeData, nil)
+
+	handler := NewNodeTypeHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/product/1", strings.NewReader(requestBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "typeId", Value: "product"}, {Key: "id", Value: "1"}}
+
+	handler.UpdateApi(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateApi_MultipartWithJSONPart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockNodeTypeService)
+	requestData := map[string]interface{}{"name": "Form Product", "price": float64(150)}
+	mockService.On("CreateRecord", "product", requestData).Return(requestData, nil)
+
+	handler := NewNodeTypeHandler(mockService)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("_json", `{"price": 150, "name": "JSON Product"}`)
+	_ = writer.WriteField("name", "Form Product")
+	_ = writer.Close()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/product", body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Params = gin.Params{{Key: "typeId", Value: "product"}}
+
+	handler.CreateApi(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateApi_UnsupportedContentType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockNodeTypeService)
+	handler := NewNodeTypeHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/product", strings.NewReader("name=abc"))
+	c.Request.Header.Set("Content-Type", "text/plain")
+	c.Params = gin.Params{{Key: "typeId", Value: "product"}}
+
+	handler.CreateApi(c)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteApi_Referenced(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockNodeTypeService)
+	mockService.On("FetchRecord", "productCategory", "1").Return(map[string]interface{}{"id": "1"}, nil)
+	mockService.On("DeleteRecord", "productCategory", "1").Return(fmt.Errorf("%w: 2 product records reference it through category", shared_dto.ErrRecordReferenced))
+
+	handler := NewNodeTypeHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/productCategory/1", nil)
+	c.Params = gin.Params{
+		{Key: "typeId", Value: "productCategory"},
+		{Key: "id", Value: "1"},
+	}
+
+	handler.DeleteApi(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "2 product records reference it through category")
+
+	mockService.AssertExpectations(t)
+}
+
+func TestRelatedApi_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockNodeTypeService)
+	mockService.On("ResolveRecordID", "productCategory", "1").Return("1", nil)
+	mockService.On("FetchRecord", "productCategory", "1").Return(map[string]interface{}{"id": "1"}, nil)
+	mockService.On("FetchRelatedRecords", "productCategory", "1", "product", "category").Return([]map[string]interface{}{{"id": "p1"}}, nil)
+
+	handler := NewNodeTypeHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, "/productCategory/1/related/product?via=category", nil)
+	c.Params = gin.Params{
+		{Key: "typeId", Value: "productCategory"},
+		{Key: "id", Value: "1"},
+		{Key: "relatedTypeId", Value: "product"},
+	}
+
+	handler.RelatedApi(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"items":[{"id":"p1"}]`)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestRelatedApi_UnknownRelatedType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockNodeTypeService)
+	mockService.On("ResolveRecordID", "productCategory", "1").Return("1", nil)
+	mockService.On("FetchRecord", "productCategory", "1").Return(map[string]interface{}{"id": "1"}, nil)
+	mockService.On("FetchRelatedRecords", "productCategory", "1", "missing", "").Return(nil, fmt.Errorf("%w: missing", shared_dto.ErrNodeTypeNotFound))
+
+	handler := NewNodeTypeHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, "/productCategory/1/related/missing", nil)
+	c.Params = gin.Params{
+		{Key: "typeId", Value: "productCategory"},
+		{Key: "id", Value: "1"},
+		{Key: "relatedTypeId", Value: "missing"},
+	}
+
+	handler.RelatedApi(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAggregateApi_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	aggregate := shared_utils.AggregateOption{
+		GroupBy: "category",
+		Metrics: map[string]string{"sum": "price", "avg": "price", "min": "", "max": ""},
+	}
+	mockService := new(MockNodeTypeService)
+	mockService.On("AggregateRecords", "product", aggregate).Return([]map[string]interface{}{
+		{"group": map[string]interface{}{"category": map[string]interface{}{"id": "c1", "label": "Shoes"}}, "count": 2, "sum": map[string]interface{}{"price": 30}},
+	}, nil)
+
+	handler := NewNodeTypeHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, "/aggregate/product?groupBy=category&sum=price&avg=price", nil)
+	c.Params = gin.Params{{Key: "typeId", Value: "product"}}
+
+	handler.AggregateApi(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"group":{"category":{"id":"c1","label":"Shoes"}}`)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAggregateApi_InvalidField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockNodeTypeService)
+	mockService.On("AggregateRecords", "product", mock.Anything).Return(nil, fmt.Errorf("%w: cannot compute sum of name, it is not numeric", shared_dto.ErrInvalidQuery))
+
+	handler := NewNodeTypeHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, "/aggregate/product?sum=name", nil)
+	c.Params = gin.Params{{Key: "typeId", Value: "product"}}
+
+	handler.AggregateApi(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestReadApi_IncludeReverse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockNodeTypeService)
+	mockService.On("ResolveRecordID", "productCategory", "1").Return("1", nil)
+	mockService.On("FetchRecord", "productCategory", "1").Return(map[string]interface{}{"id": "1"}, nil)
+	mockService.On("FetchReverseRelations", "productCategory", "1", "product.category").Return(map[string][]map[string]interface{}{
+		"product.category": {{"id": "p1"}},
+	}, nil)
+
+	handler := NewNodeTypeHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, "/productCategory/1?includeReverse=product.category", nil)
+	c.Params = gin.Params{
+		{Key: "typeId", Value: "productCategory"},
+		{Key: "id", Value: "1"},
+	}
+
+	handler.ReadApi(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"1","_related":{"product.category":[{"id":"p1"}]}}`, w.Body.String())
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,104 @@
+package node_type_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jsonPartName is the multipart field that may carry a JSON object merged into the form values.
+const jsonPartName = "_json"
+
+var ErrUnsupportedContentType = errors.New("unsupported content type, expected application/json or multipart/form-data")
+
+// readRequestData reads the record values from a JSON or multipart request body.
+// JSON numbers are kept as json.Number so that they are converted by the value type of their property.
+func readRequestData(c *gin.Context) (map[string]interface{}, error) {
+	contentType := c.ContentType()
+	switch {
+	case contentType == gin.MIMEMultipartPOSTForm:
+		return readMultipartData(c)
+	case contentType == "" || contentType == gin.MIMEJSON || strings.HasSuffix(contentType, "+json"):
+		return decodeJSONObject(c.Request.Body)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+}
+
+func readMultipartData(c *gin.Context) (map[string]interface{}, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	rawData := make(map[string]interface{})
+	if values := form.Value[jsonPartName]; len(values) > 0 {
+		if err := mergeJSONPart(rawData, strings.NewReader(values[0])); err != nil {
+			return nil, err
+		}
+	}
+	if files := form.File[jsonPartName]; len(files) > 0 {
+		part, err := files[0].Open()
+		if err != nil {
+			return nil, err
+		}
+		err = mergeJSONPart(rawData, part)
+		part.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for key, values := range form.Value {
+		if key == jsonPartName || len(values) == 0 {
+			continue
+		}
+		if len(values) == 1 {
+			rawData[key] = values[0]
+			continue
+		}
+		// Repeated fields, e.g. the ids of a REFERENCES property.
+		items := make([]interface{}, len(values))
+		for i, v := range values {
+			items[i] = v
+		}
+		rawData[key] = items
+	}
+	for key, files := range form.File {
+		if key != jsonPartName && len(files) > 0 {
+			rawData[key] = files[0]
+		}
+	}
+	return rawData, nil
+}
+
+func mergeJSONPart(rawData map[string]interface{}, r io.Reader) error {
+	data, err := decodeJSONObject(r)
+	if err != nil {
+		return fmt.Errorf("invalid %s part: %w", jsonPartName, err)
+	}
+	for k, v := range data {
+		rawData[k] = v
+	}
+	return nil
+}
+
+func decodeJSONObject(r io.Reader) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var data map[string]interface{}
+	if err := decoder.Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("request body is empty")
+		}
+		return nil, err
+	}
+	if data == nil {
+		return nil, errors.New("request body must be a JSON object")
+	}
+	return data, nil
+}
@@ -1,6 +1,10 @@
 package node_type_model
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+
 	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
 	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
 	"gorm.io/gorm"
@@ -18,14 +22,40 @@ func (n *NodeType) BeforeCreate(_ *gorm.DB) (err error) {
 	return
 }
 
+// What happens to the records referencing a deleted record, declared with onDelete on REFERENCE and
+// REFERENCES properties.
+const (
+	OnDeleteRestrict = "restrict"
+	OnDeleteCascade  = "cascade"
+	OnDeleteSetNull  = "setNull"
+)
+
 type PropertyType struct {
 	gorm.Model
 	ID             string `gorm:"primaryKey;type:char(8);index"`
 	NodeTypeRefer  string
-	PID            string `json:"pid" gorm:"column:pid"`
-	ValueType      string `json:"valueType"`
-	ReferenceType  string `json:"referenceType"`
-	ReferenceValue string `json:"referenceValue"`
+	PID            string   `json:"pid" gorm:"column:pid"`
+	ValueType      string   `json:"valueType"`
+	ReferenceType  string   `json:"referenceType"`
+	ReferenceValue string   `json:"referenceValue"`
+	Required       bool     `json:"required"`
+	Min            *float64 `json:"min"`
+	Max            *float64 `json:"max"`
+	MinLength      *int     `json:"minLength"`
+	MaxLength      *int     `json:"maxLength"`
+	Pattern        string   `json:"pattern"`
+	Enum           []string `json:"enum" gorm:"serializer:json"`
+	OnDelete       string   `json:"onDelete"`
+	Searchable     bool     `json:"searchable"`
+	SearchWeight   string   `json:"searchWeight"`
+	// JSONSchema validates the values of a JSON property.
+	JSONSchema map[string]interface{} `json:"jsonSchema" gorm:"serializer:json"`
+	// Options are the allowed values of an ENUM property.
+	Options []shared_dto.EnumOptionDTO `json:"options" gorm:"serializer:json"`
+	// SlugSource is the property a SLUG is generated from, SlugOnUpdate regenerates it when the source changes.
+	SlugSource   string `json:"slugSource"`
+	SlugOnUpdate bool   `json:"slugOnUpdate"`
+	RenamedFrom  string `json:"renamedFrom,omitempty" gorm:"-"`
 }
 
 func (pt *PropertyType) BeforeCreate(_ *gorm.DB) (err error) {
@@ -33,13 +63,262 @@ func (pt *PropertyType) BeforeCreate(_ *gorm.DB) (err error) {
 	return
 }
 
+// identifierPattern restricts tids and pids, which name the tables and columns of the schema, to letters and digits.
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*$`)
+
+func validateIdentifier(kind, name string) error {
+	if !identifierPattern.MatchString(name) {
+		return fmt.Errorf("%s %q must start with a letter and contain only letters and digits", kind, name)
+	}
+	return nil
+}
+
+// Validate checks that the validation rules declared on the property are consistent.
+func (pt *PropertyType) Validate() error {
+	if err := validateIdentifier("pid", pt.PID); err != nil {
+		return err
+	}
+	if len(pt.RenamedFrom) > 0 {
+		if err := validateIdentifier("renamedFrom", pt.RenamedFrom); err != nil {
+			return fmt.Errorf("property %s: %w", pt.PID, err)
+		}
+	}
+	if len(pt.ReferenceType) > 0 {
+		if err := validateIdentifier("referenceType", pt.ReferenceType); err != nil {
+			return fmt.Errorf("property %s: %w", pt.PID, err)
+		}
+	}
+	if pt.Min != nil && pt.Max != nil && *pt.Min > *pt.Max {
+		return fmt.Errorf("property %s: min must not be greater than max", pt.PID)
+	}
+	if pt.MinLength != nil && pt.MaxLength != nil && *pt.MinLength > *pt.MaxLength {
+		return fmt.Errorf("property %s: minLength must not be greater than maxLength", pt.PID)
+	}
+	if len(pt.Pattern) > 0 {
+		if _, err := regexp.Compile(pt.Pattern); err != nil {
+			return fmt.Errorf("property %s: invalid pattern: %w", pt.PID, err)
+		}
+	}
+	switch pt.OnDelete {
+	case "", OnDeleteRestrict, OnDeleteCascade, OnDeleteSetNull:
+	default:
+		return fmt.Errorf("property %s: onDelete must be %s, %s or %s", pt.PID, OnDeleteRestrict, OnDeleteCascade, OnDeleteSetNull)
+	}
+	if len(pt.OnDelete) > 0 && len(pt.ReferenceType) == 0 {
+		return fmt.Errorf("property %s: onDelete requires a referenceType", pt.PID)
+	}
+	switch pt.SearchWeight {
+	case "", "A", "B", "C", "D":
+	default:
+		return fmt.Errorf("property %s: searchWeight must be A, B, C or D", pt.PID)
+	}
+	if len(pt.SearchWeight) > 0 && !pt.Searchable {
+		return fmt.Errorf("property %s: searchWeight requires searchable", pt.PID)
+	}
+	if pt.JSONSchema != nil {
+		if pt.ValueType != "JSON" {
+			return fmt.Errorf("property %s: jsonSchema only applies to JSON properties", pt.PID)
+		}
+		if err := shared_utils.CheckJSONSchema(pt.JSONSchema); err != nil {
+			return fmt.Errorf("property %s: invalid jsonSchema: %w", pt.PID, err)
+		}
+	}
+	if err := pt.validateOptions(); err != nil {
+		return err
+	}
+	if pt.ValueType == "SLUG" && len(pt.SlugSource) == 0 {
+		return fmt.Errorf("property %s: SLUG properties need a slugSource", pt.PID)
+	}
+	if pt.ValueType != "SLUG" && (len(pt.SlugSource) > 0 || pt.SlugOnUpdate) {
+		return fmt.Errorf("property %s: slugSource and slugOnUpdate only apply to SLUG properties", pt.PID)
+	}
+	if pt.RenamedFrom == pt.PID && len(pt.RenamedFrom) > 0 {
+		return fmt.Errorf("property %s: renamedFrom must differ from pid", pt.PID)
+	}
+	return nil
+}
+
+var colorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+func (pt *PropertyType) validateOptions() error {
+	if pt.ValueType != "ENUM" {
+		if len(pt.Options) > 0 {
+			return fmt.Errorf("property %s: options only apply to ENUM properties", pt.PID)
+		}
+		return nil
+	}
+	if len(pt.Options) == 0 {
+		return fmt.Errorf("property %s: ENUM properties need options", pt.PID)
+	}
+	if len(pt.Enum) > 0 {
+		return fmt.Errorf("property %s: ENUM properties declare their values with options, not enum", pt.PID)
+	}
+	values := make(map[string]bool, len(pt.Options))
+	for _, option := range pt.Options {
+		if len(option.Value) == 0 {
+			return fmt.Errorf("property %s: every option needs a value", pt.PID)
+		}
+		if values[option.Value] {
+			return fmt.Errorf("property %s: duplicate option %s", pt.PID, option.Value)
+		}
+		values[option.Value] = true
+		if len(option.Color) > 0 && !colorPattern.MatchString(option.Color) {
+			return fmt.Errorf("property %s: option %s: color must be a hex color such as #1e90ff", pt.PID, option.Value)
+		}
+	}
+	renamed := make(map[string]bool)
+	for _, option := range pt.Options {
+		if len(option.RenamedFrom) == 0 {
+			continue
+		}
+		if values[option.RenamedFrom] {
+			return fmt.Errorf("property %s: option %s is renamed from %s, which is still an option", pt.PID, option.Value, option.RenamedFrom)
+		}
+		if renamed[option.RenamedFrom] {
+			return fmt.Errorf("property %s: option %s is renamed more than once", pt.PID, option.RenamedFrom)
+		}
+		renamed[option.RenamedFrom] = true
+	}
+	return nil
+}
+
+// SortedOptions returns the options by order, then as declared, without the renames of the last schema load
+// and labelled with their value when they have no label.
+func (pt *PropertyType) SortedOptions() []shared_dto.EnumOptionDTO {
+	if len(pt.Options) == 0 {
+		return nil
+	}
+	options := make([]shared_dto.EnumOptionDTO, len(pt.Options))
+	for i, option := range pt.Options {
+		option.RenamedFrom = ""
+		if len(option.Label) == 0 {
+			option.Label = option.Value
+		}
+		options[i] = option
+	}
+	sort.SliceStable(options, func(i, j int) bool { return options[i].Order < options[j].Order })
+	return options
+}
+
+// AssignDefinition copies every schema-defined attribute of src onto pt, keeping its identity.
+func (pt *PropertyType) AssignDefinition(src *PropertyType) {
+	pt.ValueType = src.ValueType
+	pt.ReferenceType = src.ReferenceType
+	pt.ReferenceValue = src.ReferenceValue
+	pt.Required = src.Required
+	pt.Min = src.Min
+	pt.Max = src.Max
+	pt.MinLength = src.MinLength
+	pt.MaxLength = src.MaxLength
+	pt.Pattern = src.Pattern
+	pt.Enum = src.Enum
+	pt.OnDelete = src.OnDelete
+	pt.Searchable = src.Searchable
+	pt.SearchWeight = src.SearchWeight
+	pt.JSONSchema = src.JSONSchema
+	pt.Options = src.SortedOptions()
+	pt.SlugSource = src.SlugSource
+	pt.SlugOnUpdate = src.SlugOnUpdate
+}
+
 func (pt *PropertyType) PropertyTypeDTO() shared_dto.PropertyTypeDTO {
 	return shared_dto.PropertyTypeDTO{
 		PID:            pt.PID,
 		ValueType:      pt.ValueType,
 		ReferenceType:  pt.ReferenceType,
 		ReferenceValue: pt.ReferenceValue,
+		Required:       pt.Required,
+		Min:            pt.Min,
+		Max:            pt.Max,
+		MinLength:      pt.MinLength,
+		MaxLength:      pt.MaxLength,
+		Pattern:        pt.Pattern,
+		Enum:           pt.Enum,
+		OnDelete:       pt.OnDelete,
+		Searchable:     pt.Searchable,
+		SearchWeight:   pt.SearchWeight,
+		JSONSchema:     pt.JSONSchema,
+		Options:        pt.SortedOptions(),
+		SlugSource:     pt.SlugSource,
+		SlugOnUpdate:   pt.SlugOnUpdate,
+	}
+}
+
+func (n *NodeType) Validate() error {
+	if len(n.TID) == 0 {
+		return fmt.Errorf("tid is required")
+	}
+	if err := validateIdentifier("tid", n.TID); err != nil {
+		return err
+	}
+	pids := make(map[string]bool, len(n.PropertyTypes))
+	slugs := 0
+	for _, pt := range n.PropertyTypes {
+		if len(pt.PID) == 0 {
+			return fmt.Errorf("nodeType %s: every property type needs a pid", n.TID)
+		}
+		if pids[pt.PID] {
+			return fmt.Errorf("nodeType %s: duplicate property %s", n.TID, pt.PID)
+		}
+		pids[pt.PID] = true
+		if err := pt.Validate(); err != nil {
+			return err
+		}
+		if pt.ValueType != "SLUG" {
+			continue
+		}
+		// A single slug keeps the alternate key of the records unambiguous.
+		if slugs++; slugs > 1 {
+			return fmt.Errorf("nodeType %s: only one SLUG property is allowed", n.TID)
+		}
+		source := n.FindPropertyType(pt.SlugSource)
+		if source == nil || source == pt {
+			return fmt.Errorf("property %s: slugSource %s is not another property of %s", pt.PID, pt.SlugSource, n.TID)
+		}
+		switch source.ValueType {
+		case "STRING", "RICH_TEXT", "ENUM", "INT":
+		default:
+			return fmt.Errorf("property %s: a slug cannot be generated from %s properties", pt.PID, source.ValueType)
+		}
+	}
+	return nil
+}
+
+func (n *NodeType) FindPropertyType(pid string) *PropertyType {
+	for _, pt := range n.PropertyTypes {
+		if pt.PID == pid {
+			return pt
+		}
+	}
+	return nil
+}
+
+// SetPropertyType replaces the property type with the same pid, or appends pt when there is none.
+func (n *NodeType) SetPropertyType(pt *PropertyType) {
+	if !n.ReplacePropertyType(pt.PID, pt) {
+		n.PropertyTypes = append(n.PropertyTypes, pt)
+	}
+}
+
+// ReplacePropertyType puts pt at the position of the property type pid, pt may carry another pid.
+func (n *NodeType) ReplacePropertyType(pid string, pt *PropertyType) bool {
+	for i, current := range n.PropertyTypes {
+		if current.PID == pid {
+			n.PropertyTypes[i] = pt
+			return true
+		}
+	}
+	return false
+}
+
+func (n *NodeType) RemovePropertyType(pid string) bool {
+	for i, pt := range n.PropertyTypes {
+		if pt.PID == pid {
+			n.PropertyTypes = append(n.PropertyTypes[:i], n.PropertyTypes[i+1:]...)
+			return true
+		}
 	}
+	return false
 }
 
 func (n *NodeType) NodeTypeDTO() shared_dto.NodeTypeDTO {
@@ -0,0 +1,83 @@
+package node_type_model
+
+import (
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func productNodeType() *NodeType {
+	return &NodeType{
+		TID: "product",
+		PropertyTypes: []*PropertyType{
+			{PID: "name", ValueType: "STRING", Required: true},
+			{PID: "price", ValueType: "DOUBLE"},
+		},
+	}
+}
+
+func TestNodeTypeValidate_Identifiers(t *testing.T) {
+	nodeType := &NodeType{TID: "product", PropertyTypes: []*PropertyType{{PID: "name", ValueType: "STRING"}}}
+	assert.NoError(t, nodeType.Validate())
+
+	for _, invalid := range []*PropertyType{
+		{PID: "x text); DROP TABLE product; --", ValueType: "STRING"},
+		{PID: "unit_price", ValueType: "STRING"},
+		{PID: "1name", ValueType: "STRING"},
+		{PID: `na"me`, ValueType: "STRING"},
+		{PID: "title", ValueType: "STRING", RenamedFrom: "name; --"},
+		{PID: "category", ValueType: "REFERENCE", ReferenceType: "category); --"},
+	} {
+		nodeType := &NodeType{TID: "product", PropertyTypes: []*PropertyType{invalid}}
+		assert.Error(t, nodeType.Validate(), "%+v", invalid)
+	}
+
+	nodeType.TID = "product; DROP TABLE node_types"
+	assert.Error(t, nodeType.Validate())
+}
+
+func TestPropertyTypeValidate_Options(t *testing.T) {
+	status := &PropertyType{PID: "status", ValueType: "ENUM", Options: []shared_dto.EnumOptionDTO{
+		{Value: "published", Label: "Published", Order: 2, Color: "#2e7d32"},
+		{Value: "draft", Order: 1},
+	}}
+	assert.NoError(t, status.Validate())
+	assert.Equal(t, []shared_dto.EnumOptionDTO{
+		{Value: "draft", Label: "draft", Order: 1},
+		{Value: "published", Label: "Published", Order: 2, Color: "#2e7d32"},
+	}, status.PropertyTypeDTO().Options)
+
+	for _, invalid := range []*PropertyType{
+		{PID: "status", ValueType: "ENUM"},
+		{PID: "status", ValueType: "STRING", Options: []shared_dto.EnumOptionDTO{{Value: "draft"}}},
+		{PID: "status", ValueType: "ENUM", Options: []shared_dto.EnumOptionDTO{{Value: "draft"}, {Value: "draft"}}},
+		{PID: "status", ValueType: "ENUM", Options: []shared_dto.EnumOptionDTO{{Value: "draft", Color: "green"}}},
+		{PID: "status", ValueType: "ENUM", Options: []shared_dto.EnumOptionDTO{{Value: "draft"}, {Value: "live", RenamedFrom: "draft"}}},
+		{PID: "status", ValueType: "ENUM", Enum: []string{"draft"}, Options: []shared_dto.EnumOptionDTO{{Value: "draft"}}},
+	} {
+		assert.Error(t, invalid.Validate(), "%+v", invalid)
+	}
+}
+
+func TestNodeTypeValidate_Slug(t *testing.T) {
+	nodeType := productNodeType()
+	nodeType.SetPropertyType(&PropertyType{PID: "slug", ValueType: "SLUG", SlugSource: "name", SlugOnUpdate: true})
+	assert.NoError(t, nodeType.Validate())
+	assert.Equal(t, "name", nodeType.FindPropertyType("slug").PropertyTypeDTO().SlugSource)
+
+	for _, invalid := range []*PropertyType{
+		{PID: "slug", ValueType: "SLUG"},
+		{PID: "slug", ValueType: "SLUG", SlugSource: "missing"},
+		{PID: "slug", ValueType: "SLUG", SlugSource: "slug"},
+		{PID: "slug", ValueType: "SLUG", SlugSource: "price"},
+		{PID: "slug", ValueType: "STRING", SlugSource: "name"},
+	} {
+		nodeType := productNodeType()
+		nodeType.SetPropertyType(invalid)
+		assert.Error(t, nodeType.Validate(), "%+v", invalid)
+	}
+
+	nodeType.SetPropertyType(&PropertyType{PID: "handle", ValueType: "SLUG", SlugSource: "name"})
+	assert.Error(t, nodeType.Validate())
+}
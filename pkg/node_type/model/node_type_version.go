@@ -0,0 +1,52 @@
+package node_type_model
+
+import (
+	"encoding/json"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
+	"gorm.io/gorm"
+)
+
+// NodeTypeVersion is an immutable snapshot of a node type definition stored on every schema load.
+type NodeTypeVersion struct {
+	gorm.Model
+	ID         string                 `gorm:"primaryKey;type:char(8);index"`
+	TID        string                 `json:"tid" gorm:"column:tid;uniqueIndex:idx_node_type_versions_tid_version"`
+	Version    int                    `json:"version" gorm:"uniqueIndex:idx_node_type_versions_tid_version"`
+	Note       string                 `json:"note"`
+	Definition shared_dto.NodeTypeDTO `json:"definition" gorm:"serializer:json"`
+	Statements []string               `json:"statements" gorm:"serializer:json"`
+	// Renames lets a rollback rename the properties and options back instead of dropping them.
+	Renames []shared_dto.SchemaRenameDTO `json:"renames" gorm:"serializer:json"`
+}
+
+func (v *NodeTypeVersion) BeforeCreate(_ *gorm.DB) (err error) {
+	v.ID = shared_utils.RandomID(4)
+	return
+}
+
+func (v *NodeTypeVersion) NodeTypeVersionDTO() shared_dto.NodeTypeVersionDTO {
+	return shared_dto.NodeTypeVersionDTO{
+		TID:           v.TID,
+		Version:       v.Version,
+		Note:          v.Note,
+		CreatedAt:     v.CreatedAt,
+		PropertyTypes: v.Definition.PropertyTypes,
+		Statements:    v.Statements,
+		Renames:       v.Renames,
+	}
+}
+
+// NodeTypeFromDTO rebuilds a schema definition from its DTO, which shares the JSON shape of the schema files.
+func NodeTypeFromDTO(dto shared_dto.NodeTypeDTO) (*NodeType, error) {
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return nil, err
+	}
+	var nodeType NodeType
+	if err := json.Unmarshal(data, &nodeType); err != nil {
+		return nil, err
+	}
+	return &nodeType, nil
+}
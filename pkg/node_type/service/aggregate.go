@@ -0,0 +1,36 @@
+package node_type_service
+
+import (
+	"github.com/ledaian41/go-cms-service/pkg/helper/sql_helper"
+	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
+)
+
+// AggregateRecords counts the records of tid matching the filters of option per group of aggregate.GroupBy,
+// with the sum, average, minimum and maximum of the numeric fields listed in aggregate.Metrics.
+func (s *NodeTypeService) AggregateRecords(tid string, option shared_utils.QueryOption, aggregate shared_utils.AggregateOption) ([]map[string]interface{}, error) {
+	db, rq, err := s.prepareRecordQuery(tid, option)
+	if err != nil {
+		return nil, err
+	}
+	db, _, err = applyFilters(db, rq.schema, option)
+	if err != nil {
+		return nil, err
+	}
+	aggregation, err := sql_helper.NewAggregation(rq.schema, aggregate.GetGroupBy(), aggregate.GetMetrics())
+	if err != nil {
+		return nil, err
+	}
+	if joins := rq.schema.JoinSQL(); len(joins) > 0 {
+		db = db.Joins(joins)
+	}
+
+	db = db.Select(aggregation.Select()).Order(aggregation.OrderBy())
+	if groupBy := aggregation.GroupBy(); len(groupBy) > 0 {
+		db = db.Group(groupBy)
+	}
+	var rows []map[string]interface{}
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return aggregation.Format(rows), nil
+}
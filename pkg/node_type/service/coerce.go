@@ -0,0 +1,49 @@
+package node_type_service
+
+import (
+	"fmt"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+)
+
+// CoerceRecord converts the raw request values to the value types declared by the node type.
+// Unknown properties and values that cannot be converted are reported together as a ValidationError.
+func (s *NodeTypeService) CoerceRecord(nodeTypeDTO shared_dto.NodeTypeDTO, rawData map[string]interface{}) (map[string]interface{}, error) {
+	return CoerceRecord(nodeTypeDTO.PropertyTypes, rawData)
+}
+
+func CoerceRecord(propertyTypes []shared_dto.PropertyTypeDTO, rawData map[string]interface{}) (map[string]interface{}, error) {
+	propertyTypeMap := make(map[string]shared_dto.PropertyTypeDTO, len(propertyTypes))
+	for _, pt := range propertyTypes {
+		propertyTypeMap[pt.PID] = pt
+	}
+
+	result := make(map[string]interface{}, len(rawData))
+	validationErr := &shared_dto.ValidationError{}
+	for key, value := range rawData {
+		pt, ok := propertyTypeMap[key]
+		if !ok {
+			validationErr.Add(key, "unknown", fmt.Sprintf("%s is not a property of this node type", key))
+			continue
+		}
+
+		valueType, err := value_type.ParseValueType(pt.ValueType)
+		if err != nil {
+			validationErr.Add(key, "type", err.Error())
+			continue
+		}
+
+		coerced, err := value_type.Coerce(valueType, value)
+		if err != nil {
+			validationErr.Add(key, "type", fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		result[key] = coerced
+	}
+
+	if validationErr.HasErrors() {
+		return nil, validationErr
+	}
+	return result, nil
+}
@@ -0,0 +1,28 @@
+package node_type_service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoerceRecord(t *testing.T) {
+	data, err := CoerceRecord(productPropertyTypes, map[string]interface{}{"name": "Shoes", "price": "19.9"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "Shoes", "price": 19.9}, data)
+}
+
+func TestCoerceRecord_ReportsUnknownAndInvalidFields(t *testing.T) {
+	_, err := CoerceRecord(productPropertyTypes, map[string]interface{}{"price": "cheap", "color": "red"})
+
+	var validationErr *shared_dto.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	rules := map[string]string{}
+	for _, f := range validationErr.Fields {
+		rules[f.Field] = f.Rule
+	}
+	assert.Equal(t, map[string]string{"price": "type", "color": "unknown"}, rules)
+}
@@ -0,0 +1,176 @@
+package node_type_service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/ledaian41/go-cms-service/pkg/helper/sql_helper"
+	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+	"gorm.io/gorm"
+)
+
+// inboundReference is a REFERENCE or REFERENCES property of another node type pointing at a node type.
+type inboundReference struct {
+	TID       string
+	PID       string
+	ValueType string
+	OnDelete  string
+}
+
+// fetchInboundReferences returns the properties referencing tid.
+func fetchInboundReferences(tx *gorm.DB, tid string) ([]inboundReference, error) {
+	var references []inboundReference
+	err := tx.Table("property_types AS pt").
+		Select("nt.tid AS tid, pt.pid AS pid, pt.value_type AS value_type, pt.on_delete AS on_delete").
+		Joins("JOIN node_types AS nt ON nt.id = pt.node_type_refer AND nt.deleted_at IS NULL").
+		Where("pt.reference_type = ? AND pt.deleted_at IS NULL", strcase.ToLowerCamel(tid)).
+		Order("nt.tid, pt.pid").
+		Scan(&references).Error
+	return references, err
+}
+
+func (ref inboundReference) table() string {
+	return strcase.ToSnake(ref.TID)
+}
+
+func (ref inboundReference) column() string {
+	return sql_helper.QuoteIdentifier(ref.table()) + "." + sql_helper.QuoteIdentifier(strcase.ToSnake(ref.PID))
+}
+
+// Key names the relation in responses, `<tid>.<pid>`.
+func (ref inboundReference) Key() string {
+	return ref.TID + "." + ref.PID
+}
+
+// condition matches the rows of the referencing table pointing at one of ids.
+func (ref inboundReference) condition(ids []string) (string, interface{}) {
+	if ref.ValueType == string(value_type.References) {
+		return ref.column() + " && ?", value_type.TextArray(ids)
+	}
+	return ref.column() + " IN ?", ids
+}
+
+// softDelete marks the records deleted and applies the onDelete behaviour of the properties referencing them:
+// restrict fails with ErrRecordReferenced, cascade deletes the referencing records with the same deleted_at
+// and setNull removes the ids from them.
+func softDelete(tx *gorm.DB, tid string, ids []string, deletedAt time.Time, visited map[string]bool) error {
+	pending := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if key := strcase.ToSnake(tid) + ":" + id; !visited[key] {
+			visited[key] = true
+			pending = append(pending, id)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	references, err := fetchInboundReferences(tx, tid)
+	if err != nil {
+		return err
+	}
+	for _, ref := range references {
+		if ref.OnDelete != node_type_model.OnDeleteRestrict {
+			continue
+		}
+		condition, value := ref.condition(pending)
+		var count int64
+		if err := tx.Table(ref.table()).Where("deleted_at IS NULL").Where(condition, value).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: %d %s records reference it through %s, delete or change them first", shared_dto.ErrRecordReferenced, count, ref.TID, ref.PID)
+		}
+	}
+
+	if err := tx.Table(strcase.ToSnake(tid)).Where("id IN ? AND deleted_at IS NULL", pending).Update("deleted_at", deletedAt).Error; err != nil {
+		return err
+	}
+
+	for _, ref := range references {
+		condition, value := ref.condition(pending)
+		switch ref.OnDelete {
+		case node_type_model.OnDeleteCascade:
+			var referencing []string
+			if err := tx.Table(ref.table()).Where("deleted_at IS NULL").Where(condition, value).Pluck("id", &referencing).Error; err != nil {
+				return err
+			}
+			if err := softDelete(tx, ref.TID, referencing, deletedAt, visited); err != nil {
+				return err
+			}
+		case node_type_model.OnDeleteSetNull:
+			if ref.ValueType == string(value_type.References) {
+				for _, id := range pending {
+					if err := tx.Table(ref.table()).Where(ref.column()+" && ?", value_type.TextArray{id}).Updates(map[string]interface{}{
+						strcase.ToSnake(ref.PID): gorm.Expr("array_remove("+ref.column()+", ?)", id),
+						"modified_at":            deletedAt,
+					}).Error; err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := tx.Table(ref.table()).Where(condition, value).Updates(map[string]interface{}{
+				strcase.ToSnake(ref.PID): nil,
+				"modified_at":            deletedAt,
+			}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restore clears deleted_at of the records, failing with ErrReferenceDeleted when one of them references
+// a deleted record through a property declaring onDelete, then restores the records its deletion cascaded to.
+func (s *NodeTypeService) restore(tx *gorm.DB, tid string, ids []string, deletedAt time.Time) error {
+	table := strcase.ToSnake(tid)
+	for _, pt := range s.FetchPropertyTypesByTid(tid) {
+		if len(pt.OnDelete) == 0 || len(pt.ReferenceType) == 0 {
+			continue
+		}
+		join := "r.id = t." + sql_helper.QuoteIdentifier(strcase.ToSnake(pt.PID))
+		if pt.ValueType == string(value_type.References) {
+			join = "r.id = ANY(t." + sql_helper.QuoteIdentifier(strcase.ToSnake(pt.PID)) + ")"
+		}
+		var count int64
+		err := tx.Table(sql_helper.QuoteIdentifier(strcase.ToSnake(pt.ReferenceType))+" AS r").
+			Joins("JOIN "+sql_helper.QuoteIdentifier(table)+" AS t ON "+join).
+			Where("t.id IN ? AND r.deleted_at IS NOT NULL", ids).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: %s references a deleted %s record, restore it first", shared_dto.ErrReferenceDeleted, pt.PID, pt.ReferenceType)
+		}
+	}
+
+	if err := tx.Table(table).Where("id IN ? AND deleted_at IS NOT NULL", ids).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+
+	references, err := fetchInboundReferences(tx, tid)
+	if err != nil {
+		return err
+	}
+	for _, ref := range references {
+		if ref.OnDelete != node_type_model.OnDeleteCascade {
+			continue
+		}
+		condition, value := ref.condition(ids)
+		var referencing []string
+		if err := tx.Table(ref.table()).Where("deleted_at = ?", deletedAt).Where(condition, value).Pluck("id", &referencing).Error; err != nil {
+			return err
+		}
+		if len(referencing) > 0 {
+			if err := s.restore(tx, ref.TID, referencing, deletedAt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
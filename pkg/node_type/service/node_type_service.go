@@ -19,7 +19,7 @@ func NewNodeTypeService(db *gorm.DB, fileService shared_interface.FileService) *
 }
 
 func (s *NodeTypeService) InitDatabase() {
-	err := s.db.AutoMigrate(&node_type_model.NodeType{}, &node_type_model.PropertyType{})
+	err := s.db.AutoMigrate(&node_type_model.NodeType{}, &node_type_model.PropertyType{}, &node_type_model.NodeTypeVersion{})
 	if err != nil {
 		log.Printf("❌ Failed at AutoMigrate: %v", err)
 	}
@@ -40,7 +40,7 @@ func (s *NodeTypeService) FetchNodeTypes() *[]shared_dto.NodeTypeDTO {
 
 func (s *NodeTypeService) FetchNodeType(tid string) shared_dto.NodeTypeDTO {
 	var node node_type_model.NodeType
-	if err := s.db.Preload("PropertyTypes").Where("tid = ?", tid).First(&node).Error; err != nil {
+	if err := s.db.Preload("PropertyTypes").Where("tid = ?", strcase.ToLowerCamel(tid)).First(&node).Error; err != nil {
 		log.Printf("❌ Failed at query NodeTypes: %v", err)
 	}
 	return node.NodeTypeDTO()
@@ -74,7 +74,7 @@ func (s *NodeTypeService) CheckNodeTypeExist(tid string) bool {
 
 func (s *NodeTypeService) FetchPropertyTypesByTid(tid string) []shared_dto.PropertyTypeDTO {
 	var nodeTypeId string
-	s.db.Table("node_types").Select("id").Where("tid = ?", tid).Scan(&nodeTypeId)
+	s.db.Table("node_types").Select("id").Where("tid = ?", strcase.ToLowerCamel(tid)).Scan(&nodeTypeId)
 	var propertyTypes []node_type_model.PropertyType
 	s.db.Table("property_types").Where("node_type_refer = ?", nodeTypeId).Find(&propertyTypes)
 	result := make([]shared_dto.PropertyTypeDTO, 0)
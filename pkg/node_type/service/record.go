@@ -1,9 +1,13 @@
 package node_type_service
 
 import (
+	"fmt"
 	"slices"
+	"strings"
 	"time"
 
+	"github.com/iancoleman/strcase"
+	"github.com/ledaian41/go-cms-service/config"
 	"github.com/ledaian41/go-cms-service/pkg/helper/sql_helper"
 	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
 	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
@@ -11,7 +15,139 @@ import (
 	"gorm.io/gorm"
 )
 
+// recordQuery holds what list and read queries share: the schema fields resolve against,
+// the expanded references and the selected columns.
+type recordQuery struct {
+	schema     *sql_helper.QuerySchema
+	expansions []*sql_helper.Expansion
+	// formatColumns are the columns whose scanned values are converted by format.
+	formatColumns map[string]value_type.ValueType
+	// richTextFormat is the representation RICH_TEXT columns are returned in.
+	richTextFormat string
+	selectFields   string
+}
+
+// prepareRecordQuery expands the reference paths of referenceView, and those used by `<reference>.<field>`
+// entries of fields, and selects the projected columns. The references used by filters and sorts are
+// joined separately, see QuerySchema.AutoJoin.
+func (s *NodeTypeService) prepareRecordQuery(tid string, option shared_utils.QueryOption) (*gorm.DB, *recordQuery, error) {
+	db := s.db.Table(tid)
+	db = db.Where(sql_helper.QuoteIdentifier(tid) + ".deleted_at IS NULL")
+
+	richTextFormat, err := value_type.ParseRichTextFormat(option.Format)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", shared_dto.ErrInvalidQuery, err)
+	}
+
+	propertyTypes := s.FetchPropertyTypesByTid(tid)
+	rq := &recordQuery{
+		schema:         sql_helper.NewQuerySchema(tid, propertyTypes),
+		formatColumns:  make(map[string]value_type.ValueType),
+		richTextFormat: richTextFormat,
+	}
+	for _, pt := range propertyTypes {
+		if vt := value_type.ValueType(pt.ValueType); vt.NeedsFormat() {
+			rq.formatColumns[strcase.ToSnake(pt.PID)] = vt
+		}
+	}
+
+	fields := option.GetFields()
+	var referenceView []string
+	for _, path := range option.GetReferenceViewKeys() {
+		if path = strings.TrimSpace(path); len(path) > 0 && !slices.Contains(referenceView, path) {
+			referenceView = append(referenceView, path)
+		}
+	}
+	for _, field := range fields {
+		if i := strings.LastIndex(field, "."); i > 0 && !slices.Contains(referenceView, field[:i]) {
+			referenceView = append(referenceView, field[:i])
+		}
+	}
+
+	maxDepth := config.DefaultMaxReferenceDepth
+	if config.Env != nil && config.Env.MaxReferenceDepth > 0 {
+		maxDepth = config.Env.MaxReferenceDepth
+	}
+	expansions, err := sql_helper.BuildExpansions(propertyTypes, referenceView, maxDepth, s.FetchPropertyTypesByTid)
+	if err != nil {
+		return nil, nil, err
+	}
+	rq.expansions = expansions
+
+	rq.schema.AutoJoin(s.FetchPropertyTypesByTid, maxDepth)
+
+	selectFields, err := sql_helper.BuildProjection(rq.schema, tid, fields, expansions)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(expansions) > 0 {
+		selectFields += ", " + sql_helper.ExpansionSelect(tid, expansions)
+	}
+	rq.selectFields = selectFields
+	return db, rq, nil
+}
+
+func (rq *recordQuery) format(records []map[string]interface{}) []map[string]interface{} {
+	for _, record := range records {
+		delete(record, sql_helper.SearchColumn)
+		for column, vt := range rq.formatColumns {
+			value, exists := record[column]
+			if !exists {
+				continue
+			}
+			if vt == value_type.RichText {
+				record[column] = value_type.FormatRichText(value, rq.richTextFormat)
+			} else {
+				record[column] = value_type.Format(vt, value)
+			}
+		}
+	}
+	sql_helper.FormatExpansions(records, rq.expansions, rq.richTextFormat)
+	sql_helper.FormatHighlights(records)
+	return records
+}
+
+// applyFilters narrows db to the records matching the filters, the filter expression and the full-text query
+// of option. The full-text search is returned to rank and highlight the results, nil without query.
+func applyFilters(db *gorm.DB, schema *sql_helper.QuerySchema, option shared_utils.QueryOption) (*gorm.DB, *sql_helper.FullTextSearch, error) {
+	searchQuery := option.GetSearchQuery()
+	if len(searchQuery) > 0 {
+		whereClause, values, err := sql_helper.BuildSearchConditions(schema, searchQuery)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(whereClause) > 0 {
+			db = db.Where(whereClause, values...)
+		}
+	}
+	filter, err := option.GetFilterExpression()
+	if err != nil {
+		return nil, nil, err
+	}
+	if filter != nil {
+		condition, values, err := sql_helper.BuildFilterExpression(schema, filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		db = db.Where(condition, values...)
+	}
+	if len(option.Search) == 0 {
+		return db, nil, nil
+	}
+	search, err := sql_helper.NewFullTextSearch(schema, option.Search)
+	if err != nil {
+		return nil, nil, err
+	}
+	join, values := search.Join()
+	return db.Joins(join, values...).Where(search.Condition()), search, nil
+}
+
 func (s *NodeTypeService) FetchRecords(tid string, option shared_utils.QueryOption) ([]map[string]interface{}, *shared_dto.PaginationDTO, error) {
+	return s.fetchRecords(tid, option, nil)
+}
+
+// fetchRecords lists the records of tid matching option, scope narrows the rows further when not nil.
+func (s *NodeTypeService) fetchRecords(tid string, option shared_utils.QueryOption, scope func(*gorm.DB) *gorm.DB) ([]map[string]interface{}, *shared_dto.PaginationDTO, error) {
 	var records []map[string]interface{}
 	if option.Page < 1 {
 		option.Page = 1 // default page number
@@ -21,114 +157,204 @@ func (s *NodeTypeService) FetchRecords(tid string, option shared_utils.QueryOpti
 		option.PageSize = 10 // default page size
 	}
 	offset := (int(option.Page) - 1) * int(option.PageSize)
-	db := s.db.Table(tid)
-	db = db.Where("deleted_at IS NULL")
-
-	var hasReference bool
-	var joinSpec sql_helper.JoinSpec
-	referenceView := option.GetReferenceViewKeys()
-	if len(referenceView) > 0 {
-		propertyTypes := s.FetchPropertyTypesByTid(tid)
-		var referencePts []shared_dto.PropertyTypeDTO
-		for _, pt := range propertyTypes {
-			contain := slices.Contains(referenceView, pt.PID)
-			reference := string(value_type.Reference)
-			if contain && (pt.ValueType == reference || pt.ValueType == string(value_type.References)) {
-				referencePts = append(referencePts, pt)
-			}
+
+	db, rq, err := s.prepareRecordQuery(tid, option)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema := rq.schema
+	if scope != nil {
+		db = db.Scopes(scope)
+	}
+
+	db, search, err := applyFilters(db, schema, option)
+	if err != nil {
+		return nil, nil, err
+	}
+	sorts, err := schema.ParseSort(option.SortBy)
+	if err != nil {
+		return nil, nil, err
+	}
+	selectFields := rq.selectFields
+	if search != nil {
+		if len(sorts) == 0 {
+			sorts = []sql_helper.SortField{search.Rank()}
 		}
-		hasReference = len(referencePts) > 0
-		if hasReference {
-			joinSpec = sql_helper.NewJoinSpec(tid, referencePts)
-			query := sql_helper.QueryJoin(joinSpec)
-			if len(query) > 0 {
-				db.Select(sql_helper.BuildSelectFields(tid, joinSpec)).Joins(query)
-			}
+		if option.Highlight {
+			selectFields += ", " + search.HighlightColumns()
 		}
 	}
+	keyset := sql_helper.NewKeyset(schema, sorts)
+	if joins := schema.JoinSQL(); len(joins) > 0 {
+		db = db.Joins(joins)
+	}
 
-	searchQuery := option.GetSearchQuery()
-	if len(searchQuery) > 0 {
-		whereClause, values := sql_helper.BuildSearchConditions(searchQuery)
-		if values != nil {
-			db = db.Where(whereClause, values...)
+	pagination := &shared_dto.PaginationDTO{PageSize: option.PageSize}
+	if !option.SkipCount {
+		var total int64
+		if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, nil, err
 		}
-	}
-	if len(option.SortBy) > 0 {
-		db.Order(option.SortBy)
+		pagination.Total = &total
+		pagination.CalculateTotalPage()
 	}
 
-	var total int64
-	db.Count(&total)
+	if option.UseCursor {
+		if len(option.Cursor) > 0 {
+			condition, values, err := keyset.Condition(option.Cursor)
+			if err != nil {
+				return nil, nil, err
+			}
+			db = db.Where(condition, values...)
+		}
+	} else {
+		pagination.Page = option.Page
+		db = db.Offset(offset)
+	}
 
-	db.Limit(int(option.PageSize)).Offset(offset)
+	// One more row than the page tells whether there is a next page without counting.
+	db = db.Select(selectFields + ", " + keyset.SelectColumns()).Order(keyset.OrderBy()).Limit(int(option.PageSize) + 1)
 	if err := db.Find(&records).Error; err != nil {
 		return nil, nil, err
 	}
 
-	pagination := &shared_dto.PaginationDTO{
-		Page:     option.Page,
-		PageSize: option.PageSize,
-		Total:    total,
+	if len(records) > int(option.PageSize) {
+		records = records[:option.PageSize]
+		pagination.HasNext = true
+		if pagination.NextCursor, err = keyset.NextCursor(records[len(records)-1]); err != nil {
+			return nil, nil, err
+		}
 	}
-	pagination.CalculateTotalPage()
-
-	if hasReference {
-		records = sql_helper.FormatJoinResponse(records, joinSpec)
+	for _, record := range records {
+		sql_helper.StripCursorColumns(record)
 	}
-	return records, pagination, nil
+
+	return rq.format(records), pagination, nil
 }
 
-func (s *NodeTypeService) FetchRecord(tid string, id string) (map[string]interface{}, error) {
-	var result map[string]interface{}
-	if err := s.db.Table(tid).Where("id = ? AND deleted_at IS NULL", id).Find(&result).Error; err != nil {
+// FetchRecord reads one record, option only applies its fields and referenceView.
+func (s *NodeTypeService) FetchRecord(tid string, id string, option shared_utils.QueryOption) (map[string]interface{}, error) {
+	db, rq, err := s.prepareRecordQuery(tid, option)
+	if err != nil {
 		return nil, err
 	}
-	if result == nil {
+	var records []map[string]interface{}
+	if err := db.Select(rq.selectFields).Where(sql_helper.QuoteIdentifier(tid)+".id = ?", id).Limit(1).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
 		return nil, nil
 	}
-	return result, nil
+	return rq.format(records)[0], nil
 }
 
 func (s *NodeTypeService) CreateRecord(tid string, data map[string]interface{}) (map[string]interface{}, error) {
+	propertyTypes := s.FetchPropertyTypesByTid(tid)
+	slug, err := s.assignSlug(tid, "", propertyTypes, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateRecord(propertyTypes, data, false); err != nil {
+		return nil, err
+	}
+	if err := s.validateReferenceTargets(propertyTypes, data); err != nil {
+		return nil, err
+	}
 	data["id"] = sql_helper.GenerateID()
 	data["created_at"] = time.Now()
 	data["modified_at"] = time.Now()
-	if result := s.db.Table(tid).Create(&data); result.Error != nil {
-		return data, result.Error
+	err = s.writeWithSlug(tid, "", propertyTypes, slug, data, func() error {
+		return s.db.Table(tid).Create(&data).Error
+	})
+	if err != nil {
+		return data, err
 	}
 	delete(data, "@id")
 	return data, nil
 }
 
 func (s *NodeTypeService) UpdateRecord(tid string, id string, data map[string]interface{}) (map[string]interface{}, error) {
+	propertyTypes := s.FetchPropertyTypesByTid(tid)
+	slug, err := s.assignSlug(tid, id, propertyTypes, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateRecord(propertyTypes, data, true); err != nil {
+		return nil, err
+	}
+	if err := s.validateReferenceTargets(propertyTypes, data); err != nil {
+		return nil, err
+	}
 	delete(data, "id")
 	data["modified_at"] = time.Now()
-	result := s.db.Table(tid).Where("id = ? AND deleted_at IS NULL", id).Updates(&data)
-	if result.Error != nil {
-		return nil, result.Error
+	err = s.writeWithSlug(tid, id, propertyTypes, slug, data, func() error {
+		return s.db.Table(tid).Where("id = ? AND deleted_at IS NULL", id).Updates(&data).Error
+	})
+	if err != nil {
+		return nil, err
 	}
 	return data, nil
 }
 
+// DeleteRecord soft deletes the record and applies the onDelete behaviour of the references to it.
 func (s *NodeTypeService) DeleteRecord(tid string, id string) error {
-	// Soft delete: mark deleted_at and optionally deleted_by
-	updates := map[string]interface{}{
-		"deleted_at": time.Now(),
-	}
 	// NOTE: if you have the actor/user id available at handler layer, pass it down and set deleted_by accordingly.
-	// e.g., updates["deleted_by"] = actorID
-	return s.db.Table(tid).Where("id = ? AND deleted_at IS NULL", id).Updates(updates).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return softDelete(tx, tid, []string{id}, time.Now(), make(map[string]bool))
+	})
 }
 
+// RestoreRecord clears deleted_at of a soft-deleted record together with the records its deletion cascaded to.
 func (s *NodeTypeService) RestoreRecord(tid string, id string) error {
-	// Soft-restore: clear deleted_at and deleted_by only if the record is currently soft-deleted
-	updates := map[string]interface{}{
-		"deleted_at": nil,
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var deletedAt []time.Time
+		if err := tx.Table(tid).Where("id = ? AND deleted_at IS NOT NULL", id).Pluck("deleted_at", &deletedAt).Error; err != nil {
+			return err
+		}
+		if len(deletedAt) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return s.restore(tx, tid, []string{id}, deletedAt[0])
+	})
+}
+
+// validateReferenceTargets checks that the records referenced by REFERENCE and REFERENCES values exist.
+func (s *NodeTypeService) validateReferenceTargets(propertyTypes []shared_dto.PropertyTypeDTO, data map[string]interface{}) error {
+	validationErr := &shared_dto.ValidationError{}
+	for _, pt := range propertyTypes {
+		if len(pt.ReferenceType) == 0 {
+			continue
+		}
+		var ids []string
+		switch value := data[pt.PID].(type) {
+		case string:
+			if pt.ValueType == string(value_type.Reference) && len(strings.TrimSpace(value)) > 0 {
+				ids = []string{value}
+			}
+		case value_type.TextArray:
+			ids = value
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		var found []string
+		if err := s.db.Table(strcase.ToSnake(pt.ReferenceType)).Where("id IN ? AND deleted_at IS NULL", ids).Pluck("id", &found).Error; err != nil {
+			return err
+		}
+		var missing []string
+		for _, id := range ids {
+			if !slices.Contains(found, id) {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			validationErr.Add(pt.PID, "reference", fmt.Sprintf("%s references %s records that do not exist: %s", pt.PID, pt.ReferenceType, strings.Join(missing, ", ")))
+		}
 	}
-	result := s.db.Table(tid).Where("id = ? AND deleted_at IS NOT NULL", id).Updates(updates)
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+
+	if validationErr.HasErrors() {
+		return validationErr
 	}
-	return result.Error
+	return nil
 }
@@ -0,0 +1,49 @@
+package node_type_service
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/helper/sql_helper"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db
+}
+
+func TestApplyFilters_IsNull(t *testing.T) {
+	db := dryRunDB(t)
+
+	schema := sql_helper.NewQuerySchema("product", productPropertyTypes)
+	option := shared_utils.QueryOption{Query: url.Values{"sku_isnull": {"true"}}}
+	filtered, _, err := applyFilters(db.Table("product"), schema, option)
+	require.NoError(t, err)
+
+	var records []map[string]interface{}
+	statement := filtered.Find(&records).Statement
+	assert.Contains(t, statement.SQL.String(), `"product"."sku" IS NULL`)
+}
+
+func TestApplyFilters_Search(t *testing.T) {
+	propertyTypes := append([]shared_dto.PropertyTypeDTO{}, productPropertyTypes...)
+	propertyTypes[0].Searchable = true
+	schema := sql_helper.NewQuerySchema("product", propertyTypes)
+	option := shared_utils.QueryOption{Search: `blue's shirt?`}
+	filtered, search, err := applyFilters(dryRunDB(t).Table("product"), schema, option)
+	require.NoError(t, err)
+	require.NotNil(t, search)
+
+	var records []map[string]interface{}
+	statement := filtered.Find(&records).Statement
+	assert.Contains(t, statement.SQL.String(), `CROSS JOIN websearch_to_tsquery('simple', $1) AS "__search_query"`)
+	assert.Contains(t, statement.SQL.String(), `"product"."search_vector" @@ "__search_query"`)
+	assert.Equal(t, []interface{}{`blue's shirt?`}, statement.Vars)
+}
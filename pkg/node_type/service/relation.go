@@ -0,0 +1,103 @@
+package node_type_service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+	"gorm.io/gorm"
+)
+
+// maxReverseRecords is how many records includeReverse returns per relation.
+const maxReverseRecords = 100
+
+// findReverseReference returns the property of relatedTid referencing tid, via names it when there are several.
+func (s *NodeTypeService) findReverseReference(tid, relatedTid, via string) (inboundReference, error) {
+	references, err := fetchInboundReferences(s.db, tid)
+	if err != nil {
+		return inboundReference{}, err
+	}
+
+	var candidates []inboundReference
+	for _, ref := range references {
+		if ref.table() != strcase.ToSnake(relatedTid) {
+			continue
+		}
+		if len(via) == 0 || strcase.ToSnake(ref.PID) == strcase.ToSnake(via) {
+			candidates = append(candidates, ref)
+		}
+	}
+
+	switch {
+	case len(candidates) == 1:
+		return candidates[0], nil
+	case len(candidates) > 1:
+		pids := make([]string, len(candidates))
+		for i, ref := range candidates {
+			pids[i] = ref.PID
+		}
+		return inboundReference{}, fmt.Errorf("%w: %s references %s through %s, choose one with via", shared_dto.ErrInvalidQuery, relatedTid, tid, strings.Join(pids, ", "))
+	case !s.CheckNodeTypeExist(strcase.ToLowerCamel(relatedTid)):
+		return inboundReference{}, fmt.Errorf("%w: %s", shared_dto.ErrNodeTypeNotFound, relatedTid)
+	case len(via) > 0:
+		return inboundReference{}, fmt.Errorf("%w: %s has no reference %s to %s", shared_dto.ErrInvalidQuery, relatedTid, via, tid)
+	}
+	return inboundReference{}, fmt.Errorf("%w: %s has no reference to %s", shared_dto.ErrInvalidQuery, relatedTid, tid)
+}
+
+// referencing narrows a query on the table of ref to the records pointing at id.
+func (ref inboundReference) referencing(id string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if ref.ValueType == string(value_type.References) {
+			return db.Where(ref.column()+" @> ?", value_type.TextArray{id})
+		}
+		return db.Where(ref.column()+" = ?", id)
+	}
+}
+
+// FetchRelatedRecords lists the records of relatedTid whose via property references the record id of tid.
+func (s *NodeTypeService) FetchRelatedRecords(tid, id, relatedTid, via string, option shared_utils.QueryOption) ([]map[string]interface{}, *shared_dto.PaginationDTO, error) {
+	ref, err := s.findReverseReference(tid, relatedTid, via)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.fetchRecords(ref.table(), option, ref.referencing(id))
+}
+
+// FetchReverseRelations returns the records referencing the record id of tid, keyed by `<tid>.<pid>`.
+// spec is `true` for every reference to tid, or a comma-separated list of `<tid>` or `<tid>.<via>`.
+func (s *NodeTypeService) FetchReverseRelations(tid, id, spec string) (map[string][]map[string]interface{}, error) {
+	var references []inboundReference
+	if spec == "true" {
+		var err error
+		if references, err = fetchInboundReferences(s.db, tid); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, entry := range strings.Split(spec, ",") {
+			entry = strings.TrimSpace(entry)
+			if len(entry) == 0 {
+				continue
+			}
+			relatedTid, via, _ := strings.Cut(entry, ".")
+			ref, err := s.findReverseReference(tid, relatedTid, via)
+			if err != nil {
+				return nil, err
+			}
+			references = append(references, ref)
+		}
+	}
+
+	relations := make(map[string][]map[string]interface{}, len(references))
+	for _, ref := range references {
+		records, _, err := s.fetchRecords(ref.table(), shared_utils.QueryOption{PageSize: maxReverseRecords, SkipCount: true}, ref.referencing(id))
+		if err != nil {
+			return nil, err
+		}
+		relations[ref.Key()] = records
+	}
+	return relations, nil
+}
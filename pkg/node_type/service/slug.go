@@ -0,0 +1,153 @@
+package node_type_service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ledaian41/go-cms-service/pkg/helper/sql_helper"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+)
+
+// findSlug returns the SLUG property of the node type, nil when it has none.
+func findSlug(propertyTypes []shared_dto.PropertyTypeDTO) *shared_dto.PropertyTypeDTO {
+	for i := range propertyTypes {
+		if propertyTypes[i].ValueType == string(value_type.Slug) {
+			return &propertyTypes[i]
+		}
+	}
+	return nil
+}
+
+// maxSlugAttempts bounds the writes of a record whose slug concurrent writes keep taking.
+const maxSlugAttempts = 3
+
+// assignSlug sets the slug of the record written with data, id is empty for a new record. On create the
+// slug is generated from its source unless given; on update it is only generated again with slugOnUpdate
+// when the source is written. Either way it is suffixed with `-2`, `-3`... when another record holds it.
+// The slug it was derived from is returned, empty when data holds none.
+func (s *NodeTypeService) assignSlug(tid, id string, propertyTypes []shared_dto.PropertyTypeDTO, data map[string]interface{}) (string, error) {
+	pt := findSlug(propertyTypes)
+	if pt == nil {
+		return "", nil
+	}
+
+	slug, given := data[pt.PID].(string)
+	if !given || len(slug) == 0 {
+		source, sourceGiven := data[pt.SlugSource]
+		if len(id) > 0 && !(pt.SlugOnUpdate && sourceGiven) {
+			if given {
+				data[pt.PID] = nil
+			}
+			return "", nil
+		}
+		slug = value_type.Slugify(slugSourceText(source))
+	}
+	if len(slug) == 0 {
+		data[pt.PID] = nil
+		return "", nil
+	}
+
+	unique, err := s.uniqueSlug(tid, id, strcase.ToSnake(pt.PID), slug)
+	if err != nil {
+		return "", err
+	}
+	data[pt.PID] = unique
+	return slug, nil
+}
+
+// writeWithSlug runs write, which stores data, and assigns the next free slug again when a concurrent write
+// took it in between. slug is the one returned by assignSlug.
+func (s *NodeTypeService) writeWithSlug(tid, id string, propertyTypes []shared_dto.PropertyTypeDTO, slug string, data map[string]interface{}, write func() error) error {
+	pt := findSlug(propertyTypes)
+	for attempt := 1; ; attempt++ {
+		err := write()
+		if err == nil || len(slug) == 0 || !isSlugConflict(err, tid, pt.PID) {
+			return err
+		}
+		if attempt == maxSlugAttempts {
+			return fmt.Errorf("%w: %v", shared_dto.ErrSlugTaken, data[pt.PID])
+		}
+		unique, err := s.uniqueSlug(tid, id, strcase.ToSnake(pt.PID), slug)
+		if err != nil {
+			return err
+		}
+		data[pt.PID] = unique
+	}
+}
+
+// uniqueViolation is the SQLSTATE of a write breaking a unique index.
+const uniqueViolation = "23505"
+
+// isSlugConflict reports whether err is the violation of the unique index of the SLUG property pid.
+func isSlugConflict(err error, tid, pid string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation &&
+		pgErr.ConstraintName == sql_helper.SlugIndexName(strcase.ToSnake(tid), strcase.ToSnake(pid))
+}
+
+func slugSourceText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case value_type.RichTextValue:
+		return v.Text
+	}
+	return fmt.Sprint(value)
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern, backslash being the default escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// uniqueSlug returns slug, or the first of slug-2, slug-3... no other record holds. Soft-deleted records
+// count as well since they keep their slug.
+func (s *NodeTypeService) uniqueSlug(tid, id, column, slug string) (string, error) {
+	var taken []string
+	quoted := sql_helper.QuoteIdentifier(column)
+	query := s.db.Table(tid).Where(fmt.Sprintf("(%[1]s = ? OR %[1]s LIKE ?)", quoted), slug, likeEscaper.Replace(slug)+"-%")
+	if len(id) > 0 {
+		query = query.Where("id <> ?", id)
+	}
+	if err := query.Pluck(quoted, &taken).Error; err != nil {
+		return "", err
+	}
+
+	held := make(map[string]bool, len(taken))
+	for _, value := range taken {
+		held[value] = true
+	}
+	candidate := slug
+	for n := 2; held[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d", slug, n)
+	}
+	return candidate, nil
+}
+
+// ResolveRecordID returns the id of the record key designates: key itself when it is an id, otherwise the id
+// of the record holding key as its slug. An unknown key is returned unchanged.
+func (s *NodeTypeService) ResolveRecordID(tid, key string) (string, error) {
+	pt := findSlug(s.FetchPropertyTypesByTid(tid))
+	if pt == nil || len(strings.TrimSpace(key)) == 0 {
+		return key, nil
+	}
+
+	table := sql_helper.QuoteIdentifier(tid)
+	var ids []string
+	if err := s.db.Table(tid).Where(table+".id = ? AND "+table+".deleted_at IS NULL", key).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return "", err
+	}
+	if len(ids) > 0 {
+		return key, nil
+	}
+	column := table + "." + sql_helper.QuoteIdentifier(strcase.ToSnake(pt.PID))
+	if err := s.db.Table(tid).Where(column+" = ? AND "+table+".deleted_at IS NULL", key).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return "", err
+	}
+	if len(ids) > 0 {
+		return ids[0], nil
+	}
+	return key, nil
+}
@@ -0,0 +1,43 @@
+package node_type_service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+var slugPropertyTypes = []shared_dto.PropertyTypeDTO{
+	{PID: "name", ValueType: "STRING"},
+	{PID: "urlKey", ValueType: "SLUG", SlugSource: "name"},
+}
+
+func TestWriteWithSlug_RetriesConflicts(t *testing.T) {
+	s := &NodeTypeService{db: dryRunDB(t)}
+	conflict := fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505", ConstraintName: "idx_product_url_key_key"})
+
+	writes := 0
+	data := map[string]interface{}{"urlKey": "tee"}
+	err := s.writeWithSlug("product", "", slugPropertyTypes, "tee", data, func() error {
+		if writes++; writes < maxSlugAttempts {
+			return conflict
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, maxSlugAttempts, writes)
+
+	err = s.writeWithSlug("product", "", slugPropertyTypes, "tee", data, func() error { return conflict })
+	assert.True(t, errors.Is(err, shared_dto.ErrSlugTaken))
+
+	other := &pgconn.PgError{Code: "23505", ConstraintName: "product_pkey"}
+	err = s.writeWithSlug("product", "", slugPropertyTypes, "tee", data, func() error { return other })
+	assert.Equal(t, other, err)
+}
+
+func TestLikeEscaper(t *testing.T) {
+	assert.Equal(t, `50\%\_off\\`, likeEscaper.Replace(`50%_off\`))
+}
@@ -0,0 +1,141 @@
+package node_type_service
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/shared/utils"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+)
+
+// ValidateRecord checks data against the rules declared on each property type.
+// When partial is true (updates) only the properties present in data are checked.
+func ValidateRecord(propertyTypes []shared_dto.PropertyTypeDTO, data map[string]interface{}, partial bool) error {
+	validationErr := &shared_dto.ValidationError{}
+	for _, pt := range propertyTypes {
+		value, exists := data[pt.PID]
+		if !exists && partial {
+			continue
+		}
+
+		if isEmptyValue(value) {
+			if pt.Required {
+				validationErr.Add(pt.PID, "required", fmt.Sprintf("%s is required", pt.PID))
+			}
+			continue
+		}
+
+		validateRules(pt, value, validationErr)
+	}
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func validateRules(pt shared_dto.PropertyTypeDTO, value interface{}, validationErr *shared_dto.ValidationError) {
+	if pt.JSONSchema != nil {
+		data := value
+		if j, ok := value.(value_type.JSONValue); ok {
+			data = j.Data
+		}
+		for _, violation := range shared_utils.ValidateJSONSchema(pt.JSONSchema, data) {
+			validationErr.Add(pt.PID, "jsonSchema", fmt.Sprintf("%s%s", pt.PID, violation))
+		}
+	}
+
+	if pt.Min != nil || pt.Max != nil {
+		number, ok := toFloat(value)
+		if !ok {
+			validationErr.Add(pt.PID, "type", fmt.Sprintf("%s must be a number", pt.PID))
+			return
+		}
+		if pt.Min != nil && number < *pt.Min {
+			validationErr.Add(pt.PID, "min", fmt.Sprintf("%s must be greater than or equal to %v", pt.PID, *pt.Min))
+		}
+		if pt.Max != nil && number > *pt.Max {
+			validationErr.Add(pt.PID, "max", fmt.Sprintf("%s must be less than or equal to %v", pt.PID, *pt.Max))
+		}
+	}
+
+	str, isString := value.(string)
+	if rt, ok := value.(value_type.RichTextValue); ok {
+		// The length of rich text is that of its plain text.
+		str, isString = rt.Text, true
+	}
+	if pt.MinLength != nil || pt.MaxLength != nil || len(pt.Pattern) > 0 {
+		if !isString {
+			validationErr.Add(pt.PID, "type", fmt.Sprintf("%s must be a string", pt.PID))
+			return
+		}
+	}
+
+	length := utf8.RuneCountInString(str)
+	if pt.MinLength != nil && length < *pt.MinLength {
+		validationErr.Add(pt.PID, "minLength", fmt.Sprintf("%s must be at least %d characters", pt.PID, *pt.MinLength))
+	}
+	if pt.MaxLength != nil && length > *pt.MaxLength {
+		validationErr.Add(pt.PID, "maxLength", fmt.Sprintf("%s must be at most %d characters", pt.PID, *pt.MaxLength))
+	}
+
+	if len(pt.Pattern) > 0 {
+		matched, err := regexp.MatchString(pt.Pattern, str)
+		if err != nil || !matched {
+			validationErr.Add(pt.PID, "pattern", fmt.Sprintf("%s does not match pattern %s", pt.PID, pt.Pattern))
+		}
+	}
+
+	if len(pt.Enum) > 0 && !slices.Contains(pt.Enum, fmt.Sprint(value)) {
+		validationErr.Add(pt.PID, "enum", fmt.Sprintf("%s must be one of: %s", pt.PID, strings.Join(pt.Enum, ", ")))
+	}
+
+	if len(pt.Options) > 0 && !pt.HasOption(fmt.Sprint(value)) {
+		values := make([]string, len(pt.Options))
+		for i, option := range pt.Options {
+			values[i] = option.Value
+		}
+		validationErr.Add(pt.PID, "enum", fmt.Sprintf("%s must be one of: %s", pt.PID, strings.Join(values, ", ")))
+	}
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch v := value.(type) {
+	case string:
+		return len(strings.TrimSpace(v)) == 0
+	case value_type.TextArray:
+		return len(v) == 0
+	case value_type.JSONValue:
+		return v.Data == nil
+	case value_type.RichTextValue:
+		return len(strings.TrimSpace(v.HTML)) == 0
+	}
+	return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,101 @@
+package node_type_service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/ledaian41/go-cms-service/pkg/value_type"
+	"github.com/stretchr/testify/assert"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+
+func intPtr(v int) *int { return &v }
+
+var productPropertyTypes = []shared_dto.PropertyTypeDTO{
+	{PID: "name", ValueType: "STRING", Required: true, MinLength: intPtr(3), MaxLength: intPtr(10)},
+	{PID: "price", ValueType: "DOUBLE", Required: true, Min: floatPtr(0), Max: floatPtr(1000)},
+	{PID: "sku", ValueType: "STRING", Pattern: "^[A-Z]{3}-[0-9]+$"},
+	{PID: "status", ValueType: "STRING", Enum: []string{"draft", "published"}},
+}
+
+func TestValidateRecord_Valid(t *testing.T) {
+	data := map[string]interface{}{"name": "Shoes", "price": "120.5", "sku": "SHO-1", "status": "draft"}
+	assert.NoError(t, ValidateRecord(productPropertyTypes, data, false))
+}
+
+func TestValidateRecord_ListsEveryFailingField(t *testing.T) {
+	data := map[string]interface{}{"name": "ab", "price": float64(-1), "sku": "shoe", "status": "archived"}
+	err := ValidateRecord(productPropertyTypes, data, false)
+
+	var validationErr *shared_dto.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	rules := map[string]string{}
+	for _, f := range validationErr.Fields {
+		rules[f.Field] = f.Rule
+	}
+	assert.Equal(t, map[string]string{"name": "minLength", "price": "min", "sku": "pattern", "status": "enum"}, rules)
+}
+
+func TestValidateRecord_Required(t *testing.T) {
+	err := ValidateRecord(productPropertyTypes, map[string]interface{}{"name": "  "}, false)
+
+	var validationErr *shared_dto.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Fields, 2)
+	assert.Equal(t, "required", validationErr.Fields[0].Rule)
+	assert.Equal(t, "required", validationErr.Fields[1].Rule)
+}
+
+func TestValidateRecord_PartialSkipsMissingFields(t *testing.T) {
+	assert.NoError(t, ValidateRecord(productPropertyTypes, map[string]interface{}{"status": "published"}, true))
+
+	err := ValidateRecord(productPropertyTypes, map[string]interface{}{"name": nil}, true)
+	assert.Error(t, err)
+}
+
+func TestValidateRecord_JSONSchema(t *testing.T) {
+	propertyTypes := []shared_dto.PropertyTypeDTO{{PID: "seo", ValueType: "JSON", JSONSchema: map[string]interface{}{
+		"type":       "object",
+		"required":   []interface{}{"title"},
+		"properties": map[string]interface{}{"title": map[string]interface{}{"type": "string", "maxLength": float64(5)}},
+	}}}
+
+	assert.NoError(t, ValidateRecord(propertyTypes, map[string]interface{}{"seo": value_type.JSONValue{Data: map[string]interface{}{"title": "Shoes"}}}, false))
+
+	err := ValidateRecord(propertyTypes, map[string]interface{}{"seo": value_type.JSONValue{Data: map[string]interface{}{"title": "Running shoes"}}}, false)
+	var validationErr *shared_dto.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []shared_dto.FieldError{{Field: "seo", Rule: "jsonSchema", Message: "seo/title: must be at most 5 characters"}}, validationErr.Fields)
+}
+
+func TestValidateRecord_EnumOptions(t *testing.T) {
+	propertyTypes := []shared_dto.PropertyTypeDTO{{PID: "status", ValueType: "ENUM", Options: []shared_dto.EnumOptionDTO{{Value: "draft"}, {Value: "live"}}}}
+
+	assert.NoError(t, ValidateRecord(propertyTypes, map[string]interface{}{"status": "live"}, false))
+
+	err := ValidateRecord(propertyTypes, map[string]interface{}{"status": "archived"}, false)
+	var validationErr *shared_dto.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []shared_dto.FieldError{{Field: "status", Rule: "enum", Message: "status must be one of: draft, live"}}, validationErr.Fields)
+}
+
+func TestValidateRecord_RichText(t *testing.T) {
+	propertyTypes := []shared_dto.PropertyTypeDTO{{PID: "description", ValueType: "RICH_TEXT", Required: true, MaxLength: intPtr(5)}}
+	richText := func(source string) value_type.RichTextValue {
+		rt, _ := value_type.NewRichText(value_type.RichTextHTML, source)
+		return rt
+	}
+
+	assert.NoError(t, ValidateRecord(propertyTypes, map[string]interface{}{"description": richText("<p><strong>Soft</strong></p>")}, false))
+
+	err := ValidateRecord(propertyTypes, map[string]interface{}{"description": richText("<script>alert(1)</script>")}, false)
+	var validationErr *shared_dto.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "required", validationErr.Fields[0].Rule)
+
+	err = ValidateRecord(propertyTypes, map[string]interface{}{"description": richText("<p>Soft cotton</p>")}, false)
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "maxLength", validationErr.Fields[0].Rule)
+}
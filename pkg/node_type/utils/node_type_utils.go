@@ -27,7 +27,7 @@ func ReadSchemasFromDir(path string) ([]*node_type_model.NodeType, error) {
 	pattern := filepath.Join(path, "*.json")
 	files, err := filepath.Glob(pattern)
 	if err != nil {
-		return nil, fmt.Errorf("❌ Failed to glob files in directory: %s", path, err)
+		return nil, fmt.Errorf("❌ Failed to glob files in directory %s: %w", path, err)
 	}
 
 	if len(files) == 0 {
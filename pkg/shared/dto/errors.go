@@ -0,0 +1,27 @@
+package shared_dto
+
+import "errors"
+
+var (
+	// ErrDataLoss is returned when a schema change would destroy existing values and force was not requested.
+	ErrDataLoss        = errors.New("schema change would lose data")
+	ErrVersionNotFound = errors.New("schema version not found")
+
+	ErrNodeTypeNotFound = errors.New("nodeType not found")
+	ErrNodeTypeExists   = errors.New("nodeType already exists")
+	// ErrNodeTypeReferenced is returned when dropping a node type that other node types still reference.
+	ErrNodeTypeReferenced   = errors.New("nodeType is referenced")
+	ErrPropertyTypeNotFound = errors.New("propertyType not found")
+	ErrPropertyTypeExists   = errors.New("propertyType already exists")
+
+	// ErrRecordReferenced is returned when an onDelete restrict reference blocks deleting a record.
+	ErrRecordReferenced = errors.New("record is referenced")
+	// ErrReferenceDeleted is returned when a record cannot be restored because a record it references is deleted.
+	ErrReferenceDeleted = errors.New("referenced record is deleted")
+
+	// ErrSlugTaken is returned when concurrent writes keep taking the slug of a record before it is written.
+	ErrSlugTaken = errors.New("slug is taken")
+
+	// ErrInvalidQuery is returned for filters and sort expressions that do not match the node type.
+	ErrInvalidQuery = errors.New("invalid query")
+)
@@ -1,6 +1,9 @@
 package shared_dto
 
-import "math"
+import (
+	"math"
+	"time"
+)
 
 type NodeTypeDTO struct {
 	TID           string            `json:"tid"`
@@ -8,19 +11,139 @@ type NodeTypeDTO struct {
 }
 
 type PropertyTypeDTO struct {
-	PID            string `json:"pid"`
-	ValueType      string `json:"valueType"`
-	ReferenceType  string `json:"referenceType"`
-	ReferenceValue string `json:"referenceValue"`
+	PID            string                 `json:"pid"`
+	ValueType      string                 `json:"valueType"`
+	ReferenceType  string                 `json:"referenceType"`
+	ReferenceValue string                 `json:"referenceValue"`
+	Required       bool                   `json:"required,omitempty"`
+	Min            *float64               `json:"min,omitempty"`
+	Max            *float64               `json:"max,omitempty"`
+	MinLength      *int                   `json:"minLength,omitempty"`
+	MaxLength      *int                   `json:"maxLength,omitempty"`
+	Pattern        string                 `json:"pattern,omitempty"`
+	Enum           []string               `json:"enum,omitempty"`
+	OnDelete       string                 `json:"onDelete,omitempty"`
+	Searchable     bool                   `json:"searchable,omitempty"`
+	SearchWeight   string                 `json:"searchWeight,omitempty"`
+	JSONSchema     map[string]interface{} `json:"jsonSchema,omitempty"`
+	Options        []EnumOptionDTO        `json:"options,omitempty"`
+	SlugSource     string                 `json:"slugSource,omitempty"`
+	SlugOnUpdate   bool                   `json:"slugOnUpdate,omitempty"`
 }
 
+// EnumOptionDTO is an allowed value of an ENUM property. Options are listed by Order, then as declared.
+// RenamedFrom, only read when loading a schema, moves the records holding the old value to Value.
+type EnumOptionDTO struct {
+	Value       string `json:"value"`
+	Label       string `json:"label"`
+	Order       int    `json:"order,omitempty"`
+	Color       string `json:"color,omitempty"`
+	RenamedFrom string `json:"renamedFrom,omitempty"`
+}
+
+// HasOption reports whether value is one of the options of the ENUM property.
+func (pt PropertyTypeDTO) HasOption(value string) bool {
+	for _, option := range pt.Options {
+		if option.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// PaginationDTO describes a page of records. Page is left out in cursor mode, Total and TotalPage
+// when the count was skipped.
 type PaginationDTO struct {
-	Page      int32 `json:"page"`
-	PageSize  int8  `json:"pageSize"`
-	Total     int64 `json:"total"`
-	TotalPage int   `json:"totalPage"`
+	Page       int32  `json:"page,omitempty"`
+	PageSize   int8   `json:"pageSize"`
+	Total      *int64 `json:"total,omitempty"`
+	TotalPage  *int   `json:"totalPage,omitempty"`
+	HasNext    bool   `json:"hasNext"`
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 func (pagination *PaginationDTO) CalculateTotalPage() {
-	pagination.TotalPage = int(math.Ceil(float64(pagination.Total) / float64(pagination.PageSize)))
+	if pagination.Total == nil {
+		return
+	}
+	totalPage := int(math.Ceil(float64(*pagination.Total) / float64(pagination.PageSize)))
+	pagination.TotalPage = &totalPage
+}
+
+const (
+	RiskNone = "none"
+	RiskLow  = "low"
+	RiskHigh = "high"
+)
+
+type SchemaStatementDTO struct {
+	SQL          string `json:"sql"`
+	Description  string `json:"description"`
+	Risk         string `json:"risk"`
+	AffectedRows int64  `json:"affectedRows,omitempty"`
+}
+
+// SchemaPlanDTO lists the statements a schema load would execute for one node type.
+type SchemaPlanDTO struct {
+	TID        string               `json:"tid"`
+	Action     string               `json:"action"`
+	Risk       string               `json:"risk"`
+	Blocked    bool                 `json:"blocked"`
+	Errors     []string             `json:"errors,omitempty"`
+	Warnings   []string             `json:"warnings,omitempty"`
+	Statements []SchemaStatementDTO `json:"statements"`
+}
+
+var riskLevels = map[string]int{RiskNone: 0, RiskLow: 1, RiskHigh: 2}
+
+func (plan *SchemaPlanDTO) AddStatement(sql, description, risk string, affectedRows int64) {
+	plan.Statements = append(plan.Statements, SchemaStatementDTO{
+		SQL:          sql,
+		Description:  description,
+		Risk:         risk,
+		AffectedRows: affectedRows,
+	})
+	if riskLevels[risk] > riskLevels[plan.Risk] {
+		plan.Risk = risk
+	}
+}
+
+func (plan *SchemaPlanDTO) Block(reason string) {
+	plan.Blocked = true
+	plan.Errors = append(plan.Errors, reason)
+}
+
+type NodeTypeVersionDTO struct {
+	TID           string            `json:"tid"`
+	Version       int               `json:"version"`
+	Note          string            `json:"note,omitempty"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	PropertyTypes []PropertyTypeDTO `json:"propertyTypes"`
+	Statements    []string          `json:"statements"`
+	Renames       []SchemaRenameDTO `json:"renames,omitempty"`
+}
+
+// SchemaRenameDTO is a rename applied by a schema version: of a property when PID is empty, otherwise of an
+// option of the ENUM property PID.
+type SchemaRenameDTO struct {
+	PID  string `json:"pid,omitempty"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type PropertyTypeChangeDTO struct {
+	PID    string          `json:"pid"`
+	Before PropertyTypeDTO `json:"before"`
+	After  PropertyTypeDTO `json:"after"`
+}
+
+// SchemaVersionDiffDTO describes how the definition of a node type changed between two versions.
+type SchemaVersionDiffDTO struct {
+	TID        string                  `json:"tid"`
+	From       int                     `json:"from"`
+	To         int                     `json:"to"`
+	Added      []PropertyTypeDTO       `json:"added"`
+	Removed    []PropertyTypeDTO       `json:"removed"`
+	Changed    []PropertyTypeChangeDTO `json:"changed"`
+	Statements []string                `json:"statements"`
 }
@@ -0,0 +1,30 @@
+package shared_dto
+
+import "strings"
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every field that failed the rules of its node type.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Add(field, rule, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Rule: rule, Message: message})
+}
+
+func (e *ValidationError) HasErrors() bool {
+	return len(e.Fields) > 0
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Message)
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
@@ -1,6 +1,23 @@
 package shared_interface
 
+import (
+	"github.com/ledaian41/go-cms-service/pkg/node_type/model"
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+)
+
 type HelperService interface {
-	LoadSchema(filePath string, ch chan<- string)
+	LoadSchema(filePath string, force bool, ch chan<- string)
+	PlanSchema(filePath string, force bool) ([]shared_dto.SchemaPlanDTO, error)
+	FetchVersions(tid string) ([]shared_dto.NodeTypeVersionDTO, error)
+	DiffVersions(tid string, from, to int) (*shared_dto.SchemaVersionDiffDTO, error)
+	Rollback(tid string, version int, force bool) (*shared_dto.SchemaPlanDTO, error)
 	LoadJsonData(filePath string, ch chan<- string)
+	CreateNodeType(nodeType *node_type_model.NodeType, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error)
+	ReplaceNodeType(tid string, nodeType *node_type_model.NodeType, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error)
+	PatchNodeType(tid string, propertyTypes []*node_type_model.PropertyType, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error)
+	DropNodeType(tid string, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error)
+	AddPropertyType(tid string, pt *node_type_model.PropertyType, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error)
+	ReplacePropertyType(tid, pid string, pt *node_type_model.PropertyType, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error)
+	PatchPropertyType(tid, pid string, patch map[string]interface{}, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error)
+	DeletePropertyType(tid, pid string, force, dryRun bool) (*shared_dto.SchemaPlanDTO, error)
 }
@@ -12,11 +12,16 @@ type NodeTypeService interface {
 	CheckNodeTypeExist(tid string) bool
 	FetchPropertyTypesByTid(tid string) []shared_dto.PropertyTypeDTO
 	FetchRecords(tid string, option shared_utils.QueryOption) ([]map[string]interface{}, *shared_dto.PaginationDTO, error)
-	FetchRecord(tid string, id string) (map[string]interface{}, error)
+	FetchRecord(tid string, id string, option shared_utils.QueryOption) (map[string]interface{}, error)
+	ResolveRecordID(tid string, key string) (string, error)
+	FetchRelatedRecords(tid, id, relatedTid, via string, option shared_utils.QueryOption) ([]map[string]interface{}, *shared_dto.PaginationDTO, error)
+	FetchReverseRelations(tid, id, spec string) (map[string][]map[string]interface{}, error)
+	AggregateRecords(tid string, option shared_utils.QueryOption, aggregate shared_utils.AggregateOption) ([]map[string]interface{}, error)
 	CreateRecord(tid string, data map[string]interface{}) (map[string]interface{}, error)
 	UpdateRecord(tid string, id string, data map[string]interface{}) (map[string]interface{}, error)
 	DeleteRecord(tid string, id string) error
 	RestoreRecord(tid string, id string) error
+	CoerceRecord(nodeTypeDTO shared_dto.NodeTypeDTO, rawData map[string]interface{}) (map[string]interface{}, error)
 	PreprocessFile(nodeTypeDTO shared_dto.NodeTypeDTO, rawData map[string]interface{}) (map[string]interface{}, error)
 	ProcessFilePath(record map[string]interface{})
 }
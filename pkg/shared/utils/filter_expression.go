@@ -0,0 +1,206 @@
+package shared_utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+)
+
+const (
+	FilterAnd = "AND"
+	FilterOr  = "OR"
+	FilterNot = "NOT"
+)
+
+// FilterNode is a node of a parsed filter expression. Leaves hold a Query, the other nodes
+// combine their Children with Op.
+type FilterNode struct {
+	Op       string
+	Children []*FilterNode
+	Query    *SearchQuery
+}
+
+// ParseFilterExpression parses the `filter` query parameter:
+//
+//	expr  = and { "OR" and }
+//	and   = not { "AND" not }
+//	not   = "NOT" not | "(" expr ")" | term
+//	term  = field "_" operator ":" value
+//
+// Keywords are case-insensitive and values containing spaces or parentheses are double-quoted,
+// e.g. `(status_equal:draft OR author_equal:me) AND NOT title_include:"hello world"`.
+func ParseFilterExpression(input string) (*FilterNode, error) {
+	tokens, err := tokenizeFilter(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &filterParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q in filter", shared_dto.ErrInvalidQuery, p.tokens[p.pos].text)
+	}
+	return node, nil
+}
+
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+func (t filterToken) is(keyword string) bool {
+	return !t.quoted && strings.EqualFold(t.text, keyword)
+}
+
+// tokenizeFilter splits the expression into parentheses and words. A double-quoted section,
+// with \" and \\ escapes, is part of the word it appears in.
+func tokenizeFilter(input string) ([]filterToken, error) {
+	var tokens []filterToken
+	var current strings.Builder
+	inWord, quoted := false, false
+
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, filterToken{text: current.String(), quoted: quoted})
+		}
+		current.Reset()
+		inWord, quoted = false, false
+	}
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, filterToken{text: string(r)})
+		case r == '"':
+			inWord, quoted = true, true
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					current.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					break
+				}
+				current.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("%w: unterminated quote in filter", shared_dto.ErrInvalidQuery)
+			}
+		default:
+			inWord = true
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() (filterToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *filterParser) parseOr() (*FilterNode, error) {
+	return p.parseBinary(FilterOr, p.parseAnd)
+}
+
+func (p *filterParser) parseAnd() (*FilterNode, error) {
+	return p.parseBinary(FilterAnd, p.parseNot)
+}
+
+func (p *filterParser) parseBinary(op string, operand func() (*FilterNode, error)) (*FilterNode, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	children := []*FilterNode{first}
+	for {
+		token, ok := p.peek()
+		if !ok || !token.is(op) {
+			break
+		}
+		p.pos++
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &FilterNode{Op: op, Children: children}, nil
+}
+
+func (p *filterParser) parseNot() (*FilterNode, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected end of filter", shared_dto.ErrInvalidQuery)
+	}
+	switch {
+	case token.is(FilterNot):
+		p.pos++
+		child, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &FilterNode{Op: FilterNot, Children: []*FilterNode{child}}, nil
+	case !token.quoted && token.text == "(":
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.quoted || closing.text != ")" {
+			return nil, fmt.Errorf("%w: missing ) in filter", shared_dto.ErrInvalidQuery)
+		}
+		p.pos++
+		return node, nil
+	case !token.quoted && (token.text == ")" || token.is(FilterAnd) || token.is(FilterOr)):
+		return nil, fmt.Errorf("%w: unexpected %q in filter", shared_dto.ErrInvalidQuery, token.text)
+	}
+	p.pos++
+	query, err := parseFilterTerm(token.text)
+	if err != nil {
+		return nil, err
+	}
+	return &FilterNode{Query: query}, nil
+}
+
+func parseFilterTerm(term string) (*SearchQuery, error) {
+	key, value, found := strings.Cut(term, ":")
+	if !found {
+		return nil, fmt.Errorf("%w: %q is not a {field}_{operator}:{value} term", shared_dto.ErrInvalidQuery, term)
+	}
+	field, operator, ok := splitSearchKey(key)
+	if !ok || !validOperators[operator] {
+		return nil, fmt.Errorf("%w: unknown operator in %q", shared_dto.ErrInvalidQuery, key)
+	}
+	if !checkValidQuery(operator, value) {
+		return nil, fmt.Errorf("%w: invalid value for %s", shared_dto.ErrInvalidQuery, key)
+	}
+	return &SearchQuery{Field: field, Operator: operator, Value: value}, nil
+}
@@ -0,0 +1,55 @@
+package shared_utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ledaian41/go-cms-service/pkg/shared/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	node, err := ParseFilterExpression(`(status_equal:draft or author_id_equal:me) AND NOT title_include:"hello (world)"`)
+	assert.NoError(t, err)
+
+	assert.Equal(t, FilterAnd, node.Op)
+	assert.Len(t, node.Children, 2)
+
+	or := node.Children[0]
+	assert.Equal(t, FilterOr, or.Op)
+	assert.Equal(t, &SearchQuery{Field: "status", Operator: "equal", Value: "draft"}, or.Children[0].Query)
+	assert.Equal(t, &SearchQuery{Field: "author_id", Operator: "equal", Value: "me"}, or.Children[1].Query)
+
+	not := node.Children[1]
+	assert.Equal(t, FilterNot, not.Op)
+	assert.Equal(t, &SearchQuery{Field: "title", Operator: "include", Value: "hello (world)"}, not.Children[0].Query)
+}
+
+func TestParseFilterExpression_AndBindsTighterThanOr(t *testing.T) {
+	node, err := ParseFilterExpression("a_equal:1 OR b_equal:2 AND c_equal:3")
+	assert.NoError(t, err)
+	assert.Equal(t, FilterOr, node.Op)
+	assert.Equal(t, FilterAnd, node.Children[1].Op)
+}
+
+func TestParseFilterExpression_Empty(t *testing.T) {
+	node, err := ParseFilterExpression("  ")
+	assert.NoError(t, err)
+	assert.Nil(t, node)
+}
+
+func TestParseFilterExpression_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"(status_equal:draft",
+		"status_equal:draft)",
+		"status_equal:draft OR",
+		"status_matches:draft",
+		"status",
+		`title_equal:"open`,
+		"price_fromto:10",
+		"AND status_equal:draft",
+	} {
+		_, err := ParseFilterExpression(input)
+		assert.True(t, errors.Is(err, shared_dto.ErrInvalidQuery), input)
+	}
+}
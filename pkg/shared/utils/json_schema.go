@@ -0,0 +1,320 @@
+package shared_utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchemaTypes are the values of the `type` keyword.
+var jsonSchemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true,
+}
+
+// jsonSchemaKeywords are the keywords ValidateJSONSchema enforces, the annotations that do not constrain the value
+// are accepted as well.
+var jsonSchemaKeywords = map[string]bool{
+	"type": true, "properties": true, "required": true, "additionalProperties": true, "items": true, "enum": true,
+	"minimum": true, "maximum": true, "minLength": true, "maxLength": true, "pattern": true, "minItems": true,
+	"maxItems": true, "$schema": true, "$comment": true, "title": true, "description": true, "default": true,
+	"examples": true,
+}
+
+// CheckJSONSchema reports the first keyword of schema that is unsupported or has an invalid value. Only type,
+// properties, required, additionalProperties, items, enum, minimum, maximum, minLength, maxLength, pattern, minItems
+// and maxItems are enforced, any other keyword but the annotations is refused rather than silently ignored.
+func CheckJSONSchema(schema map[string]interface{}) error {
+	return checkJSONSchema(schema, "#")
+}
+
+func checkJSONSchema(schema map[string]interface{}, path string) error {
+	keywords := make([]string, 0, len(schema))
+	for keyword := range schema {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	for _, keyword := range keywords {
+		if !jsonSchemaKeywords[keyword] {
+			return fmt.Errorf("%s: unsupported keyword %s", path, keyword)
+		}
+	}
+	if t, exists := schema["type"]; exists {
+		types, ok := schemaTypes(t)
+		if !ok {
+			return fmt.Errorf("%s: type must be a type name or an array of type names", path)
+		}
+		for _, name := range types {
+			if !jsonSchemaTypes[name] {
+				return fmt.Errorf("%s: unknown type %s", path, name)
+			}
+		}
+	}
+	if properties, exists := schema["properties"]; exists {
+		object, ok := properties.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: properties must be an object", path)
+		}
+		for name, property := range object {
+			sub, ok := property.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s/properties/%s: must be a schema", path, name)
+			}
+			if err := checkJSONSchema(sub, path+"/properties/"+name); err != nil {
+				return err
+			}
+		}
+	}
+	if required, exists := schema["required"]; exists {
+		if _, ok := stringList(required); !ok {
+			return fmt.Errorf("%s: required must be an array of property names", path)
+		}
+	}
+	for _, keyword := range []string{"additionalProperties", "items"} {
+		value, exists := schema[keyword]
+		if !exists {
+			continue
+		}
+		if _, isBool := value.(bool); isBool && keyword == "additionalProperties" {
+			continue
+		}
+		sub, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: %s must be a schema", path, keyword)
+		}
+		if err := checkJSONSchema(sub, path+"/"+keyword); err != nil {
+			return err
+		}
+	}
+	if enum, exists := schema["enum"]; exists {
+		if _, ok := enum.([]interface{}); !ok {
+			return fmt.Errorf("%s: enum must be an array", path)
+		}
+	}
+	for _, keyword := range []string{"minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems"} {
+		if value, exists := schema[keyword]; exists {
+			if _, ok := schemaNumber(value); !ok {
+				return fmt.Errorf("%s: %s must be a number", path, keyword)
+			}
+		}
+	}
+	if pattern, exists := schema["pattern"]; exists {
+		str, ok := pattern.(string)
+		if !ok {
+			return fmt.Errorf("%s: pattern must be a string", path)
+		}
+		if _, err := regexp.Compile(str); err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// ValidateJSONSchema checks value against schema and returns one message per violation, starting with the
+// JSON pointer of the offending value, which is empty for value itself.
+func ValidateJSONSchema(schema map[string]interface{}, value interface{}) []string {
+	normalized, err := normalizeJSON(value)
+	if err != nil {
+		return []string{fmt.Sprintf(": %v", err)}
+	}
+	var violations []string
+	validateJSONSchema(schema, normalized, "", &violations)
+	return violations
+}
+
+// normalizeJSON round trips value through encoding/json so that objects are maps, arrays are slices
+// and numbers are json.Number whatever Go types value was built from.
+func normalizeJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var normalized interface{}
+	err = decoder.Decode(&normalized)
+	return normalized, err
+}
+
+func validateJSONSchema(schema map[string]interface{}, value interface{}, pointer string, violations *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*violations = append(*violations, pointer+": "+fmt.Sprintf(format, args...))
+	}
+
+	if t, exists := schema["type"]; exists {
+		types, _ := schemaTypes(t)
+		if !matchesAnyType(value, types) {
+			fail("must be %s", strings.Join(types, " or "))
+			return
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, option := range enum {
+			if jsonEqual(option, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of the enum values")
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		required, _ := stringList(schema["required"])
+		for _, name := range required {
+			if _, exists := v[name]; !exists {
+				fail("missing required property %s", name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if sub, ok := properties[name].(map[string]interface{}); ok {
+				validateJSONSchema(sub, v[name], pointer+"/"+name, violations)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					fail("property %s is not allowed", name)
+				}
+			case map[string]interface{}:
+				validateJSONSchema(additional, v[name], pointer+"/"+name, violations)
+			}
+		}
+	case []interface{}:
+		if min, ok := schemaNumber(schema["minItems"]); ok && float64(len(v)) < min {
+			fail("must have at least %v items", min)
+		}
+		if max, ok := schemaNumber(schema["maxItems"]); ok && float64(len(v)) > max {
+			fail("must have at most %v items", max)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				validateJSONSchema(items, item, fmt.Sprintf("%s/%d", pointer, i), violations)
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if min, ok := schemaNumber(schema["minLength"]); ok && length < min {
+			fail("must be at least %v characters", min)
+		}
+		if max, ok := schemaNumber(schema["maxLength"]); ok && length > max {
+			fail("must be at most %v characters", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if matched, err := regexp.MatchString(pattern, v); err != nil || !matched {
+				fail("does not match pattern %s", pattern)
+			}
+		}
+	case json.Number:
+		number, _ := v.Float64()
+		if min, ok := schemaNumber(schema["minimum"]); ok && number < min {
+			fail("must be greater than or equal to %v", min)
+		}
+		if max, ok := schemaNumber(schema["maximum"]); ok && number > max {
+			fail("must be less than or equal to %v", max)
+		}
+	}
+}
+
+func matchesAnyType(value interface{}, types []string) bool {
+	for _, t := range types {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case json.Number:
+			if t == "number" {
+				return true
+			}
+			if f, err := v.Float64(); t == "integer" && err == nil && f == math.Trunc(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonEqual compares two JSON values, numbers by their value.
+func jsonEqual(a, b interface{}) bool {
+	an, aIsNumber := schemaNumber(a)
+	bn, bIsNumber := schemaNumber(b)
+	if aIsNumber || bIsNumber {
+		return aIsNumber && bIsNumber && an == bn
+	}
+	a, errA := normalizeJSON(a)
+	b, errB := normalizeJSON(b)
+	return errA == nil && errB == nil && reflect.DeepEqual(a, b)
+}
+
+func schemaTypes(value interface{}) ([]string, bool) {
+	if name, ok := value.(string); ok {
+		return []string{name}, true
+	}
+	return stringList(value)
+}
+
+func stringList(value interface{}) ([]string, bool) {
+	items, ok := value.([]interface{})
+	if !ok {
+		if list, isList := value.([]string); isList {
+			return list, true
+		}
+		return nil, false
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, str)
+	}
+	return result, true
+}
+
+// schemaNumber reads a number of a schema or a value, decoded either as float64 or json.Number.
+func schemaNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,66 @@
+package shared_utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var seoSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []interface{}{"title"},
+	"properties": map[string]interface{}{
+		"title":    map[string]interface{}{"type": "string", "minLength": float64(1), "maxLength": float64(60)},
+		"robots":   map[string]interface{}{"enum": []interface{}{"index", "noindex"}},
+		"priority": map[string]interface{}{"type": "number", "minimum": float64(0), "maximum": float64(1)},
+		"keywords": map[string]interface{}{"type": "array", "maxItems": float64(2), "items": map[string]interface{}{"type": "string", "pattern": "^[a-z]+$"}},
+	},
+	"additionalProperties": false,
+}
+
+func TestCheckJSONSchema(t *testing.T) {
+	assert.NoError(t, CheckJSONSchema(seoSchema))
+
+	for _, schema := range []map[string]interface{}{
+		{"type": "text"},
+		{"type": float64(1)},
+		{"properties": []interface{}{}},
+		{"properties": map[string]interface{}{"a": "string"}},
+		{"required": "title"},
+		{"items": true},
+		{"enum": "a"},
+		{"minimum": "0"},
+		{"pattern": "("},
+		{"properties": map[string]interface{}{"a": map[string]interface{}{"maxLength": "5"}}},
+		{"$ref": "#/definitions/seo"},
+		{"oneOf": []interface{}{map[string]interface{}{"type": "string"}}},
+		{"const": "a"},
+		{"type": "string", "format": "email"},
+		{"items": map[string]interface{}{"type": "number", "exclusiveMinimum": float64(0)}},
+	} {
+		assert.Error(t, CheckJSONSchema(schema), "%v", schema)
+	}
+
+	assert.NoError(t, CheckJSONSchema(map[string]interface{}{"title": "SEO", "description": "search metadata", "type": "object"}))
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	assert.Empty(t, ValidateJSONSchema(seoSchema, map[string]interface{}{
+		"title": "Shoes", "robots": "index", "priority": 0.5, "keywords": []string{"run", "walk"},
+	}))
+
+	assert.Equal(t, []string{
+		": missing required property title",
+		": property extra is not allowed",
+		"/keywords: must have at most 2 items",
+		"/keywords/2: does not match pattern ^[a-z]+$",
+		"/priority: must be less than or equal to 1",
+		"/robots: must be one of the enum values",
+	}, ValidateJSONSchema(seoSchema, map[string]interface{}{
+		"robots": "follow", "priority": 3, "keywords": []interface{}{"a", "b", "C"}, "extra": true,
+	}))
+
+	assert.Equal(t, []string{": must be object"}, ValidateJSONSchema(seoSchema, "title"))
+	assert.Empty(t, ValidateJSONSchema(map[string]interface{}{"type": []interface{}{"integer", "null"}}, nil))
+	assert.Equal(t, []string{": must be integer"}, ValidateJSONSchema(map[string]interface{}{"type": "integer"}, 1.5))
+}
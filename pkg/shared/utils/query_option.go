@@ -1,7 +1,6 @@
 package shared_utils
 
 import (
-	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -19,16 +18,35 @@ type QueryOption struct {
 	Page          int32
 	PageSize      int8
 	SortBy        string
+	Filter        string
+	Fields        string
+	UseCursor     bool // keyset pagination, Cursor is empty for the first page
+	Cursor        string
+	SkipCount     bool
+	Search        string // full-text query over the searchable properties
+	Highlight     bool   // adds the matching snippets of each record under `_highlight`
+	Format        string // representation of RICH_TEXT properties: html (default), markdown or text
 	Query         url.Values
 }
 
 var validOperators = map[string]bool{
-	"equal":   true,
-	"include": true,
-	"in":      true,
-	"from":    true,
-	"to":      true,
-	"fromto":  true,
+	"equal":      true,
+	"notequal":   true,
+	"include":    true,
+	"in":         true,
+	"notin":      true,
+	"from":       true,
+	"to":         true,
+	"fromto":     true,
+	"isnull":     true,
+	"notnull":    true,
+	"startswith": true,
+	"endswith":   true,
+	"like":       true,
+	"regex":      true,
+	"contains":   true,
+	"hasany":     true,
+	"hasall":     true,
 }
 
 func (qo QueryOption) GetReferenceViewKeys() []string {
@@ -38,6 +56,45 @@ func (qo QueryOption) GetReferenceViewKeys() []string {
 	return strings.Split(qo.ReferenceView, ",")
 }
 
+// GetFields returns the entries of the comma-separated fields projection, nil when every column is selected.
+func (qo QueryOption) GetFields() []string {
+	return splitList(qo.Fields)
+}
+
+// AggregateFunctions are the functions computed over numeric fields, the count of rows is always computed.
+var AggregateFunctions = []string{"sum", "avg", "min", "max"}
+
+// AggregateOption lists the fields the aggregation endpoint groups by and those each aggregate function is computed over.
+type AggregateOption struct {
+	GroupBy string            // comma-separated fields
+	Metrics map[string]string // comma-separated fields by function: sum, avg, min, max
+}
+
+func (ao AggregateOption) GetGroupBy() []string {
+	return splitList(ao.GroupBy)
+}
+
+func (ao AggregateOption) GetMetrics() map[string][]string {
+	metrics := make(map[string][]string, len(ao.Metrics))
+	for function, fields := range ao.Metrics {
+		if list := splitList(fields); len(list) > 0 {
+			metrics[function] = list
+		}
+	}
+	return metrics
+}
+
+// splitList returns the non-empty entries of a comma-separated list.
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); len(entry) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
 func (qo QueryOption) GetSearchQuery() []SearchQuery {
 	queries := make([]SearchQuery, 0)
 	for key, values := range qo.Query {
@@ -45,15 +102,8 @@ func (qo QueryOption) GetSearchQuery() []SearchQuery {
 			continue
 		}
 
-		parts := strings.Split(key, "_")
-		if len(parts) < 2 {
-			continue
-		}
-
-		field := parts[0]
-		operator := parts[len(parts)-1]
-
-		if !validOperators[operator] {
+		field, operator, ok := splitSearchKey(key)
+		if !ok || !validOperators[operator] {
 			continue
 		}
 
@@ -61,14 +111,6 @@ func (qo QueryOption) GetSearchQuery() []SearchQuery {
 			continue
 		}
 
-		if !strings.Contains(field, ".") {
-			field = fmt.Sprintf("%s.%s", qo.TypeId, field)
-		}
-
-		if strings.Count(field, ".") > 1 {
-			continue
-		}
-
 		queries = append(queries, SearchQuery{
 			Field:    field,
 			Operator: operator,
@@ -78,6 +120,20 @@ func (qo QueryOption) GetSearchQuery() []SearchQuery {
 	return queries
 }
 
+// GetFilterExpression parses the boolean filter expression of the `filter` parameter, nil when it is empty.
+func (qo QueryOption) GetFilterExpression() (*FilterNode, error) {
+	return ParseFilterExpression(qo.Filter)
+}
+
+// splitSearchKey splits `{field}_{operator}` at its last underscore, field names may contain underscores.
+func splitSearchKey(key string) (string, string, bool) {
+	separator := strings.LastIndex(key, "_")
+	if separator <= 0 || separator == len(key)-1 {
+		return "", "", false
+	}
+	return key[:separator], key[separator+1:], true
+}
+
 func checkValidQuery(operator string, value string) bool {
 	switch operator {
 	case "from":
@@ -91,6 +147,10 @@ func checkValidQuery(operator string, value string) bool {
 		if len(fromTo) != 2 || strings.TrimSpace(fromTo[0]) == "" || strings.TrimSpace(fromTo[1]) == "" {
 			return false
 		}
+	case "startswith", "endswith", "like", "regex", "contains", "hasany", "hasall":
+		if len(value) == 0 {
+			return false
+		}
 	}
 	return true
 }
@@ -0,0 +1,201 @@
+package value_type
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime/multipart"
+	"strconv"
+	"strings"
+)
+
+// Coerce converts a raw request value into the Go value stored in the column of the value type.
+// A nil value is returned unchanged so that the column can be cleared.
+func Coerce(vt ValueType, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if _, ok := value.(*multipart.FileHeader); ok {
+		if vt != File {
+			return nil, fmt.Errorf("expected %s, got a file", vt)
+		}
+		return value, nil
+	}
+
+	switch vt {
+	case Integer:
+		return coerceInteger(value)
+	case Double, Float:
+		return coerceFloat(value)
+	case Boolean:
+		return coerceBoolean(value)
+	case References:
+		return coerceReferences(value)
+	case Date:
+		return coerceDate(value)
+	case DateTime:
+		return coerceDateTime(value)
+	case Time:
+		return coerceTime(value)
+	case JSON:
+		return coerceJSON(value)
+	case RichText:
+		return coerceRichText(value)
+	case Slug:
+		return coerceSlug(value)
+	default:
+		return coerceString(vt, value)
+	}
+}
+
+// coerceInteger rejects values outside the range of the integer column instead of letting them wrap.
+func coerceInteger(value interface{}) (interface{}, error) {
+	var i int64
+	switch v := value.(type) {
+	case int:
+		i = int64(v)
+	case int32:
+		i = int64(v)
+	case int64:
+		i = v
+	case float32:
+		return coerceInteger(float64(v))
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt32 || v > math.MaxInt32 {
+			return nil, fmt.Errorf("expected an integer, got %v", v)
+		}
+		i = int64(v)
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %s", v)
+		}
+		i = n
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", v)
+		}
+		i = n
+	default:
+		return nil, fmt.Errorf("expected an integer, got %T", value)
+	}
+	if i < math.MinInt32 || i > math.MaxInt32 {
+		return nil, fmt.Errorf("integer %d is out of range [%d, %d]", i, math.MinInt32, math.MaxInt32)
+	}
+	return i, nil
+}
+
+func coerceFloat(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float32:
+		return coerceFloat(float64(v))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("expected a number, got %v", v)
+		}
+		return v, nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %s", v)
+		}
+		return f, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("expected a number, got %q", v)
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("expected a number, got %T", value)
+}
+
+// coerceBoolean returns 1 or 0 since booleans are stored in an integer column.
+func coerceBoolean(value interface{}) (interface{}, error) {
+	var b bool
+	switch v := value.(type) {
+	case bool:
+		b = v
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "1", "t", "yes", "y", "on":
+			b = true
+		case "false", "0", "f", "no", "n", "off":
+			b = false
+		default:
+			return nil, fmt.Errorf("expected a boolean, got %q", v)
+		}
+	case json.Number, float64, int, int64:
+		switch fmt.Sprint(v) {
+		case "1":
+			b = true
+		case "0":
+			b = false
+		default:
+			return nil, fmt.Errorf("expected a boolean, got %v", v)
+		}
+	default:
+		return nil, fmt.Errorf("expected a boolean, got %T", value)
+	}
+	if b {
+		return int64(1), nil
+	}
+	return int64(0), nil
+}
+
+func coerceString(vt ValueType, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number, float64, int, int64, bool:
+		if vt == File {
+			return nil, fmt.Errorf("expected a file, got %T", value)
+		}
+		return fmt.Sprint(v), nil
+	}
+	return nil, fmt.Errorf("expected a string, got %T", value)
+}
+
+// coerceReferences accepts an array of ids or a comma-separated string, blank and repeated ids are dropped.
+func coerceReferences(value interface{}) (interface{}, error) {
+	var items []interface{}
+	switch v := value.(type) {
+	case string:
+		for _, id := range strings.Split(v, ",") {
+			items = append(items, id)
+		}
+	case []string:
+		for _, id := range v {
+			items = append(items, id)
+		}
+	case TextArray:
+		for _, id := range v {
+			items = append(items, id)
+		}
+	case []interface{}:
+		items = v
+	default:
+		return nil, fmt.Errorf("expected an array of ids, got %T", value)
+	}
+
+	ids := TextArray{}
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		id, err := coerceString(References, item)
+		if err != nil {
+			return nil, fmt.Errorf("expected an array of ids: %v", err)
+		}
+		if s := strings.TrimSpace(id.(string)); len(s) > 0 && !seen[s] {
+			seen[s] = true
+			ids = append(ids, s)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,69 @@
+package value_type
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoerce(t *testing.T) {
+	cases := []struct {
+		valueType ValueType
+		input     interface{}
+		expected  interface{}
+	}{
+		{Integer, "12", int64(12)},
+		{Integer, json.Number("7"), int64(7)},
+		{Integer, float64(3), int64(3)},
+		{Integer, float32(-4), int64(-4)},
+		{Integer, int32(5), int64(5)},
+		{Integer, float64(2147483647), int64(2147483647)},
+		{Double, " 1.5 ", 1.5},
+		{Float, json.Number("2.25"), 2.25},
+		{Float, float32(0.5), 0.5},
+		{Double, int32(6), float64(6)},
+		{Boolean, "true", int64(1)},
+		{Boolean, "off", int64(0)},
+		{Boolean, false, int64(0)},
+		{String, "hello", "hello"},
+		{String, json.Number("42"), "42"},
+		{Reference, "abc123", "abc123"},
+		{References, []interface{}{"a", "b", "a"}, TextArray{"a", "b"}},
+		{References, "a, b,,c", TextArray{"a", "b", "c"}},
+		{References, []interface{}{}, TextArray{}},
+		{Integer, nil, nil},
+	}
+	for _, tc := range cases {
+		result, err := Coerce(tc.valueType, tc.input)
+		assert.NoError(t, err, "%s %v", tc.valueType, tc.input)
+		assert.Equal(t, tc.expected, result, "%s %v", tc.valueType, tc.input)
+	}
+}
+
+func TestCoerce_Invalid(t *testing.T) {
+	cases := []struct {
+		valueType ValueType
+		input     interface{}
+	}{
+		{Integer, "12.5"},
+		{Integer, float64(1.5)},
+		{Integer, "twelve"},
+		{Integer, float64(2147483648)},
+		{Integer, float64(-1e12)},
+		{Integer, "9999999999"},
+		{Integer, json.Number("-2147483649")},
+		{Integer, float32(1.5)},
+		{Double, "abc"},
+		{Boolean, "maybe"},
+		{Boolean, float64(2)},
+		{String, map[string]interface{}{"a": 1}},
+		{File, float64(1)},
+		{References, []interface{}{"a", map[string]interface{}{}}},
+		{References, float64(1)},
+	}
+	for _, tc := range cases {
+		_, err := Coerce(tc.valueType, tc.input)
+		assert.Error(t, err, "%s %v", tc.valueType, tc.input)
+	}
+}
@@ -0,0 +1,28 @@
+package value_type
+
+// NeedsFormat reports whether values of vt scanned from the database are converted by Format.
+func (vt ValueType) NeedsFormat() bool {
+	return vt == References || vt == JSON || vt == RichText || vt.IsTemporal()
+}
+
+// Format converts a value scanned from the database into its response representation:
+// text[] literals become arrays, jsonb text is decoded, rich text is returned as HTML and dates and times
+// are written as ISO-8601.
+func Format(vt ValueType, value interface{}) interface{} {
+	switch {
+	case vt == References:
+		if literal, isString := value.(string); isString {
+			if ids, ok := ParseTextArray(literal); ok {
+				return ids
+			}
+		}
+		return value
+	case vt == JSON:
+		return formatJSON(value)
+	case vt == RichText:
+		return FormatRichText(value, RichTextHTML)
+	case vt.IsTemporal():
+		return formatTemporal(vt, value)
+	}
+	return value
+}
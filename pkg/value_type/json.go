@@ -0,0 +1,73 @@
+package value_type
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSONValue is the value of a jsonb column. It is written as JSON text so that the query builder
+// does not treat objects and arrays as lists of parameters.
+type JSONValue struct {
+	Data interface{}
+}
+
+func (j JSONValue) Value() (driver.Value, error) {
+	data, err := json.Marshal(j.Data)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (j JSONValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.Data)
+}
+
+// coerceJSON accepts any JSON value, a string must hold JSON text as multipart forms and filters only carry strings.
+func coerceJSON(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case JSONValue:
+		return v, nil
+	case string:
+		data, ok := parseJSON(v)
+		if !ok {
+			return nil, fmt.Errorf("expected JSON, got %q", v)
+		}
+		return JSONValue{Data: data}, nil
+	}
+	if _, err := json.Marshal(value); err != nil {
+		return nil, fmt.Errorf("expected JSON, got %T", value)
+	}
+	return JSONValue{Data: value}, nil
+}
+
+// parseJSON decodes JSON text keeping numbers as json.Number so that large integers are not rounded.
+func parseJSON(text string) (interface{}, bool) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil || decoder.More() {
+		return nil, false
+	}
+	return data, true
+}
+
+// formatJSON decodes a jsonb column, which is scanned as its text.
+func formatJSON(value interface{}) interface{} {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(bytes.TrimSpace(v))
+	default:
+		return value
+	}
+	if data, ok := parseJSON(text); ok {
+		return data
+	}
+	return value
+}
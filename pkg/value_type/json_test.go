@@ -0,0 +1,32 @@
+package value_type
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoerce_JSON(t *testing.T) {
+	result, err := Coerce(JSON, map[string]interface{}{"title": "Shoes", "tags": []interface{}{"a"}})
+	assert.NoError(t, err)
+	value, err := result.(JSONValue).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"tags":["a"],"title":"Shoes"}`, value)
+
+	result, err = Coerce(JSON, `{"id": 9007199254740993}`)
+	assert.NoError(t, err)
+	assert.Equal(t, JSONValue{Data: map[string]interface{}{"id": json.Number("9007199254740993")}}, result)
+
+	for _, input := range []interface{}{"not json", `{"a": 1} {"b": 2}`, make(chan int)} {
+		_, err := Coerce(JSON, input)
+		assert.Error(t, err, "%v", input)
+	}
+}
+
+func TestFormat_JSON(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{"flags": []interface{}{true}, "rank": json.Number("2")}, Format(JSON, `{"flags": [true], "rank": 2}`))
+	assert.Equal(t, "draft", Format(JSON, `"draft"`))
+	assert.Nil(t, Format(JSON, nil))
+	assert.Equal(t, TextArray{"a", "b"}, Format(References, `{a,b}`))
+}
@@ -0,0 +1,21 @@
+package value_type
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// markdown renders CommonMark with GitHub strikethrough. Raw HTML is omitted.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.Strikethrough))
+
+// RenderMarkdown converts source to HTML, the result still has to be sanitised since link targets are
+// copied as written.
+func RenderMarkdown(source string) (string, error) {
+	var b strings.Builder
+	if err := markdown.Convert([]byte(source), &b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
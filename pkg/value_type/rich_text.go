@@ -0,0 +1,132 @@
+package value_type
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Formats of a RICH_TEXT source, RichTextPlain is only a representation returned on read.
+const (
+	RichTextHTML     = "html"
+	RichTextMarkdown = "markdown"
+	RichTextPlain    = "text"
+)
+
+// RichTextValue is the value of a RICH_TEXT column: the source as written together with its sanitised HTML
+// and its plain text, which filters and the full-text search use.
+type RichTextValue struct {
+	Format string `json:"format"`
+	Source string `json:"source"`
+	HTML   string `json:"html"`
+	Text   string `json:"text"`
+}
+
+// NewRichText renders source and sanitises the result against the allowlist, see SetRichTextAllowedTags.
+// An HTML source is stored sanitised, a Markdown source as written.
+func NewRichText(format, source string) (RichTextValue, error) {
+	rt := RichTextValue{Format: format, Source: source}
+	switch format {
+	case RichTextHTML:
+		rt.HTML = SanitizeHTML(source, allowedTags)
+		rt.Source = rt.HTML
+	case RichTextMarkdown:
+		rendered, err := RenderMarkdown(source)
+		if err != nil {
+			return RichTextValue{}, err
+		}
+		rt.HTML = SanitizeHTML(rendered, allowedTags)
+	default:
+		return RichTextValue{}, fmt.Errorf("rich text format must be %s or %s, got %q", RichTextHTML, RichTextMarkdown, format)
+	}
+	rt.Text = PlainText(rt.HTML)
+	return rt, nil
+}
+
+func (rt RichTextValue) Value() (driver.Value, error) {
+	data, err := json.Marshal(rt)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Representation returns the value returned for format: the HTML, the source or the plain text.
+// The source of an HTML value is returned for markdown as HTML is valid Markdown.
+func (rt RichTextValue) Representation(format string) string {
+	switch format {
+	case RichTextMarkdown:
+		return rt.Source
+	case RichTextPlain:
+		return rt.Text
+	default:
+		return rt.HTML
+	}
+}
+
+// ParseRichTextFormat checks the representation requested on read, html when empty.
+func ParseRichTextFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return RichTextHTML, nil
+	case RichTextHTML, RichTextMarkdown, RichTextPlain:
+		return format, nil
+	}
+	return "", fmt.Errorf("format must be %s, %s or %s, got %q", RichTextHTML, RichTextMarkdown, RichTextPlain, format)
+}
+
+// coerceRichText accepts an HTML string or an object with the format and the source.
+func coerceRichText(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case RichTextValue:
+		return v, nil
+	case string:
+		return NewRichText(RichTextHTML, v)
+	case map[string]interface{}:
+		format, _ := v["format"].(string)
+		if len(format) == 0 {
+			format = RichTextHTML
+		}
+		source, ok := v["source"].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected rich text with a source string, got %T", v["source"])
+		}
+		return NewRichText(strings.ToLower(format), source)
+	}
+	return nil, fmt.Errorf("expected rich text, got %T", value)
+}
+
+// formatRichText decodes a RICH_TEXT column. Values converted from a text column only have their source,
+// which is taken for HTML and rendered again.
+func formatRichText(value interface{}) (RichTextValue, bool) {
+	var rt RichTextValue
+	switch v := formatJSON(value).(type) {
+	case string:
+		rt.Format, rt.Source = RichTextHTML, v
+	case map[string]interface{}:
+		rt.Format, _ = v["format"].(string)
+		rt.Source, _ = v["source"].(string)
+		rt.HTML, _ = v["html"].(string)
+		rt.Text, _ = v["text"].(string)
+		if len(rt.HTML) > 0 || len(rt.Source) == 0 {
+			return rt, true
+		}
+	default:
+		return rt, false
+	}
+	if rendered, err := NewRichText(rt.Format, rt.Source); err == nil {
+		return rendered, true
+	}
+	rendered, _ := NewRichText(RichTextHTML, rt.Source)
+	return rendered, true
+}
+
+// FormatRichText returns the representation of a scanned RICH_TEXT column requested by format.
+func FormatRichText(value interface{}, format string) interface{} {
+	rt, ok := formatRichText(value)
+	if !ok {
+		return value
+	}
+	return rt.Representation(format)
+}
@@ -0,0 +1,79 @@
+package value_type
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	allowed := tagSet(DefaultRichTextTags)
+	for input, expected := range map[string]string{
+		`<p onclick="x()">Hi <b>there</b></p>`:                                      `<p>Hi <b>there</b></p>`,
+		`<script>alert(1)</script><p>ok</p>`:                                        `<p>ok</p>`,
+		`<div><span>kept text</span></div>`:                                         `kept text`,
+		`<a href="javascript:alert(1)" title="t">x</a>`:                             `<a title="t">x</a>`,
+		`<a href=" java	script:alert(1)">x</a>`:                                     `<a>x</a>`,
+		`<a href="/shoes?a=1&b=2">x</a>`:                                            `<a href="/shoes?a=1&amp;b=2">x</a>`,
+		`<img src="data:image/png;base64,AA" alt="a"><img src="https://cdn/x.png">`: `<img alt="a"><img src="https://cdn/x.png">`,
+		`<ul><li>one<li>two</ul></p>`:                                               `<ul><li>one<li>two</li></li></ul>`,
+		`<p>1 < 2 & "quoted"`:                                                       `<p>1 &lt; 2 &amp; &#34;quoted&#34;</p>`,
+		`<svg><svg></svg><p>hidden</p></svg>after<!-- comment -->`:                  `after`,
+	} {
+		assert.Equal(t, expected, SanitizeHTML(input, allowed), input)
+	}
+
+	SetRichTextAllowedTags([]string{"p"})
+	defer SetRichTextAllowedTags(nil)
+	rt, err := NewRichText(RichTextHTML, "<p><b>bold</b></p>")
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>bold</p>", rt.HTML)
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	source := "# Soft *cotton* tee\n\nMade of **organic** cotton, see [care](https://example.com/care) and `wash_30`.\nSecond line.\n\n" +
+		"- ~~one~~\n- two\n  continued\n\n3. three\n4. four\n\n> quoted <b>\n\n---\n\n```\n<raw> & code\n```"
+	rendered, err := RenderMarkdown(source)
+	assert.NoError(t, err)
+	assert.Equal(t, "<h1>Soft <em>cotton</em> tee</h1>\n"+
+		"<p>Made of <strong>organic</strong> cotton, see <a href=\"https://example.com/care\">care</a> and <code>wash_30</code>.\nSecond line.</p>\n"+
+		"<ul>\n<li><del>one</del></li>\n<li>two\ncontinued</li>\n</ul>\n"+
+		"<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n"+
+		"<blockquote>\n<p>quoted <!-- raw HTML omitted --></p>\n</blockquote>\n"+
+		"<hr>\n"+
+		"<pre><code>&lt;raw&gt; &amp; code\n</code></pre>\n", rendered)
+}
+
+func TestCoerce_RichText(t *testing.T) {
+	result, err := Coerce(RichText, `<h2>Soft</h2><p>Organic <img src=x onerror="alert(1)">cotton</p>`)
+	assert.NoError(t, err)
+	assert.Equal(t, RichTextValue{
+		Format: "html",
+		Source: `<h2>Soft</h2><p>Organic <img src="x">cotton</p>`,
+		HTML:   `<h2>Soft</h2><p>Organic <img src="x">cotton</p>`,
+		Text:   "Soft\nOrganic cotton",
+	}, result)
+
+	result, err = Coerce(RichText, map[string]interface{}{"format": "markdown", "source": "Hello [you](javascript:alert(1))"})
+	assert.NoError(t, err)
+	assert.Equal(t, RichTextValue{Format: "markdown", Source: "Hello [you](javascript:alert(1))", HTML: "<p>Hello <a href=\"\">you</a></p>\n", Text: "Hello you"}, result)
+
+	for _, input := range []interface{}{map[string]interface{}{"format": "bbcode", "source": "[b]x[/b]"}, map[string]interface{}{"format": "html"}, 42} {
+		_, err := Coerce(RichText, input)
+		assert.Error(t, err, "%v", input)
+	}
+}
+
+func TestFormatRichText(t *testing.T) {
+	stored := `{"format": "markdown", "source": "**Soft**", "html": "<p><strong>Soft</strong></p>", "text": "Soft"}`
+	assert.Equal(t, "<p><strong>Soft</strong></p>", Format(RichText, stored))
+	assert.Equal(t, "**Soft**", FormatRichText(stored, RichTextMarkdown))
+	assert.Equal(t, "Soft", FormatRichText(stored, RichTextPlain))
+
+	// Converted from a text column, the source has not been sanitised yet.
+	assert.Equal(t, "<p>Soft</p>", FormatRichText(`{"format": "html", "source": "<p onclick=\"x()\">Soft</p>"}`, RichTextHTML))
+	assert.Nil(t, Format(RichText, nil))
+
+	_, err := ParseRichTextFormat("pdf")
+	assert.Error(t, err)
+}
@@ -0,0 +1,184 @@
+package value_type
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// DefaultRichTextTags are the HTML tags RICH_TEXT values keep unless SetRichTextAllowedTags is called.
+var DefaultRichTextTags = []string{
+	"p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6", "strong", "b", "em", "i", "u", "s", "del", "sub", "sup",
+	"blockquote", "code", "pre", "ul", "ol", "li", "a", "img", "table", "thead", "tbody", "tr", "th", "td",
+}
+
+// richTextAttributes are the attributes kept on each tag, every other attribute is dropped.
+var richTextAttributes = map[string][]string{
+	"a":   {"href", "title"},
+	"img": {"src", "alt", "title"},
+	"ol":  {"start"},
+	"td":  {"colspan", "rowspan"},
+	"th":  {"colspan", "rowspan"},
+}
+
+// urlAttributes only keep relative URLs and those of safeSchemes.
+var (
+	urlAttributes = map[string]bool{"href": true, "src": true}
+	safeSchemes   = map[string]bool{"http": true, "https": true, "mailto": true}
+)
+
+// droppedContentTags are removed together with their content, other tags outside the allowlist only lose their markup.
+var droppedContentTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "noscript": true,
+	"template": true, "textarea": true, "select": true, "title": true, "svg": true, "math": true,
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// blockTags end a line of the plain text.
+var blockTags = map[string]bool{
+	"p": true, "br": true, "hr": true, "div": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"blockquote": true, "pre": true, "ul": true, "ol": true, "li": true, "table": true, "tr": true,
+}
+
+// allowedTags is the allowlist of RICH_TEXT values.
+var allowedTags = tagSet(DefaultRichTextTags)
+
+// SetRichTextAllowedTags replaces the allowlist of RICH_TEXT values, empty restores DefaultRichTextTags.
+// It is meant to be called once at startup, before any value is written.
+func SetRichTextAllowedTags(tags []string) {
+	if len(tags) == 0 {
+		tags = DefaultRichTextTags
+	}
+	allowedTags = tagSet(tags)
+}
+
+func tagSet(tags []string) map[string]bool {
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		set[tag] = true
+	}
+	return set
+}
+
+// SanitizeHTML keeps the allowed tags of source with their allowed attributes and escapes all text.
+// The result is well formed: unclosed tags are closed and stray end tags are dropped.
+func SanitizeHTML(source string, allowed map[string]bool) string {
+	var b strings.Builder
+	var open []string
+	// skipping is the dropped tag whose content is being skipped, depth counts its nested occurrences.
+	skipping, depth := "", 0
+	tokenizer := html.NewTokenizer(strings.NewReader(source))
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+		if len(skipping) > 0 {
+			if token.Data == skipping && tt == html.StartTagToken {
+				depth++
+			} else if token.Data == skipping && tt == html.EndTagToken {
+				if depth--; depth == 0 {
+					skipping = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			b.WriteString(html.EscapeString(token.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedContentTags[token.Data] {
+				if tt == html.StartTagToken {
+					skipping, depth = token.Data, 1
+				}
+				continue
+			}
+			if !allowed[token.Data] {
+				continue
+			}
+			writeStartTag(&b, token)
+			if !voidTags[token.Data] && tt == html.StartTagToken {
+				open = append(open, token.Data)
+			}
+		case html.EndTagToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != token.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+func writeStartTag(b *strings.Builder, token html.Token) {
+	b.WriteString("<" + token.Data)
+	for _, attr := range token.Attr {
+		if len(attr.Namespace) > 0 || !slices.Contains(richTextAttributes[token.Data], attr.Key) {
+			continue
+		}
+		if urlAttributes[attr.Key] && !isSafeURL(attr.Val) {
+			continue
+		}
+		b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	b.WriteString(">")
+}
+
+// isSafeURL rejects the schemes that run code, such as javascript: and data:, browsers ignore the
+// whitespace and control characters they may be hidden with so those reject the URL too.
+func isSafeURL(value string) bool {
+	value = strings.TrimSpace(value)
+	if strings.ContainsFunc(value, func(r rune) bool { return r < 0x21 || r == 0x7f }) {
+		return false
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	return len(u.Scheme) == 0 || safeSchemes[strings.ToLower(u.Scheme)]
+}
+
+// PlainText returns the text of sanitised HTML, one line per block with runs of whitespace collapsed.
+func PlainText(source string) string {
+	var b strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(source))
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+		switch tt {
+		case html.TextToken:
+			b.WriteString(token.Data)
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			if blockTags[token.Data] {
+				b.WriteString("\n")
+			} else if token.Data == "td" || token.Data == "th" {
+				b.WriteString(" ")
+			}
+		}
+	}
+
+	lines := make([]string, 0)
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,88 @@
+package value_type
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxSlugLength leaves room for the suffix that keeps a slug unique.
+const MaxSlugLength = 96
+
+// slugLetters are transliterated letters that do not decompose into a base letter and a mark.
+var slugLetters = map[rune]string{
+	'đ': "d", 'ð': "d", 'ß': "ss", 'æ': "ae", 'ø': "o", 'œ': "oe", 'ł': "l", 'þ': "th", 'ı': "i",
+}
+
+// Slugify lowercases text, strips the diacritics of its letters and joins the remaining runs of
+// letters and digits with hyphens, e.g. "Áo thun Đỏ!" becomes "ao-thun-do".
+func Slugify(text string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range norm.NFD.String(strings.ToLower(text)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		case len(slugLetters[r]) > 0:
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteString(slugLetters[r])
+			hyphen = false
+		default:
+			hyphen = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > MaxSlugLength {
+		slug = slug[:MaxSlugLength]
+		if i := strings.LastIndexByte(slug, '-'); i > MaxSlugLength/2 {
+			slug = slug[:i]
+		}
+		slug = strings.TrimRight(slug, "-")
+	}
+	return slug
+}
+
+// UniqueSlugs makes the slugs unique in place: the first occurrence of a slug keeps it and the later ones take
+// the first of slug-2, slug-3... that no other slug holds. Empty slugs are left empty.
+func UniqueSlugs(slugs []string) {
+	held := make(map[string]bool, len(slugs))
+	repeated := make([]int, 0)
+	for i, slug := range slugs {
+		if len(slug) == 0 {
+			continue
+		}
+		if held[slug] {
+			repeated = append(repeated, i)
+			continue
+		}
+		held[slug] = true
+	}
+	for _, i := range repeated {
+		candidate := slugs[i]
+		for n := 2; held[candidate]; n++ {
+			candidate = fmt.Sprintf("%s-%d", slugs[i], n)
+		}
+		held[candidate] = true
+		slugs[i] = candidate
+	}
+}
+
+// coerceSlug normalises a slug given explicitly the way generated slugs are written.
+func coerceSlug(value interface{}) (interface{}, error) {
+	text, err := coerceString(Slug, value)
+	if err != nil {
+		return nil, err
+	}
+	return Slugify(text.(string)), nil
+}
@@ -0,0 +1,40 @@
+package value_type
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	for text, expected := range map[string]string{
+		"Soft Cotton Tee":            "soft-cotton-tee",
+		"  Áo thun Đỏ! (size M)  ":   "ao-thun-do-size-m",
+		"Crème brûlée & Straße 2024": "creme-brulee-strasse-2024",
+		"Łódź -- Smørrebrød":         "lodz-smorrebrod",
+		"日本語":                        "",
+		"---":                        "",
+	} {
+		assert.Equal(t, expected, Slugify(text), text)
+	}
+
+	long := Slugify(strings.Repeat("cotton ", 30))
+	assert.LessOrEqual(t, len(long), MaxSlugLength)
+	assert.False(t, strings.HasSuffix(long, "-"))
+}
+
+func TestUniqueSlugs(t *testing.T) {
+	slugs := []string{"a", "a", "a-2", "", "b", "a", ""}
+	UniqueSlugs(slugs)
+	assert.Equal(t, []string{"a", "a-3", "a-2", "", "b", "a-4", ""}, slugs)
+}
+
+func TestCoerce_Slug(t *testing.T) {
+	result, err := Coerce(Slug, "My Tee")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-tee", result)
+
+	_, err = Coerce(Slug, []interface{}{"a"})
+	assert.Error(t, err)
+}
@@ -7,12 +7,26 @@ func MapValueTypeToSQL(pt *node_type_model.PropertyType) string {
 	if err != nil {
 		return ""
 	}
+	return vt.SQLType()
+}
 
+// SQLType returns the column type storing values of vt.
+func (vt ValueType) SQLType() string {
 	switch vt {
 	case Integer, Boolean:
 		return "integer"
 	case Double, Float:
 		return "real"
+	case References:
+		return "text[]"
+	case Date:
+		return "date"
+	case DateTime:
+		return "timestamptz"
+	case Time:
+		return "time"
+	case JSON, RichText:
+		return "jsonb"
 	default:
 		return "text"
 	}
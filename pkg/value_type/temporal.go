@@ -0,0 +1,139 @@
+package value_type
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DateLayout = "2006-01-02"
+	TimeLayout = "15:04:05.999999999"
+)
+
+// dateTimeLayouts are the ISO-8601 forms accepted for DATETIME values. Fractional seconds are accepted after
+// the seconds of any layout, and values without an offset are read as UTC.
+var dateTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	DateLayout,
+}
+
+var timeLayouts = []string{"15:04:05", "15:04"}
+
+// IsTemporal reports whether properties of the value type hold a date or a time of day.
+func (vt ValueType) IsTemporal() bool {
+	return vt == Date || vt == DateTime || vt == Time
+}
+
+func parseDateTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// coerceDate returns the calendar date as written, a date-time keeps the date of its own offset.
+func coerceDate(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(DateLayout), nil
+	case string:
+		if t, ok := parseDateTime(v); ok {
+			return t.Format(DateLayout), nil
+		}
+		return nil, fmt.Errorf("expected an ISO-8601 date, got %q", v)
+	}
+	return nil, fmt.Errorf("expected an ISO-8601 date, got %T", value)
+}
+
+// coerceDateTime returns the instant in UTC.
+func coerceDateTime(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC(), nil
+	case string:
+		if t, ok := parseDateTime(v); ok {
+			return t.UTC(), nil
+		}
+		return nil, fmt.Errorf("expected an ISO-8601 date-time, got %q", v)
+	}
+	return nil, fmt.Errorf("expected an ISO-8601 date-time, got %T", value)
+}
+
+func coerceTime(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(TimeLayout), nil
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t.Format(TimeLayout), nil
+			}
+		}
+		return nil, fmt.Errorf("expected an ISO-8601 time, got %q", v)
+	}
+	return nil, fmt.Errorf("expected an ISO-8601 time, got %T", value)
+}
+
+// formatTemporal writes a scanned DATE as `2006-01-02`, a DATETIME as RFC 3339 in UTC and a TIME as `15:04:05`.
+func formatTemporal(vt ValueType, value interface{}) interface{} {
+	t, isTime := value.(time.Time)
+	if !isTime {
+		return value
+	}
+	switch vt {
+	case Date:
+		return t.Format(DateLayout)
+	case Time:
+		return t.Format(TimeLayout)
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+var relativeTimePattern = regexp.MustCompile(`^now(?:([+-])([0-9]+)([smhdwMy]))?$`)
+
+// ParseRelativeTime reads `now` optionally shifted by an amount of seconds (s), minutes (m), hours (h),
+// days (d), weeks (w), months (M) or years (y), such as `now-7d`. ok is false for any other value.
+func ParseRelativeTime(value string, now time.Time) (time.Time, bool) {
+	match := relativeTimePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return time.Time{}, false
+	}
+	if len(match[1]) == 0 {
+		return now, true
+	}
+	amount, err := strconv.Atoi(match[2])
+	if err != nil {
+		return time.Time{}, false
+	}
+	if match[1] == "-" {
+		amount = -amount
+	}
+	switch match[3] {
+	case "s":
+		return now.Add(time.Duration(amount) * time.Second), true
+	case "m":
+		return now.Add(time.Duration(amount) * time.Minute), true
+	case "h":
+		return now.Add(time.Duration(amount) * time.Hour), true
+	case "d":
+		return now.AddDate(0, 0, amount), true
+	case "w":
+		return now.AddDate(0, 0, 7*amount), true
+	case "M":
+		return now.AddDate(0, amount, 0), true
+	}
+	return now.AddDate(amount, 0, 0), true
+}
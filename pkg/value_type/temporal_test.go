@@ -0,0 +1,76 @@
+package value_type
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoerce_Temporal(t *testing.T) {
+	cases := []struct {
+		valueType ValueType
+		input     interface{}
+		expected  interface{}
+	}{
+		{Date, "2024-03-09", "2024-03-09"},
+		{Date, "2024-03-09T23:30:00-05:00", "2024-03-09"},
+		{DateTime, "2024-03-09T23:30:00-05:00", time.Date(2024, 3, 10, 4, 30, 0, 0, time.UTC)},
+		{DateTime, "2024-03-09T10:15:30.5Z", time.Date(2024, 3, 9, 10, 15, 30, 500000000, time.UTC)},
+		{DateTime, "2024-03-09 10:15", time.Date(2024, 3, 9, 10, 15, 0, 0, time.UTC)},
+		{DateTime, " 2024-03-09 ", time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{Time, "08:05", "08:05:00"},
+		{Time, "08:05:09.25", "08:05:09.25"},
+	}
+	for _, tc := range cases {
+		result, err := Coerce(tc.valueType, tc.input)
+		assert.NoError(t, err, "%s %v", tc.valueType, tc.input)
+		assert.Equal(t, tc.expected, result, "%s %v", tc.valueType, tc.input)
+	}
+
+	for _, tc := range []struct {
+		valueType ValueType
+		input     interface{}
+	}{
+		{Date, "09/03/2024"},
+		{Date, float64(20240309)},
+		{DateTime, "2024-13-01T00:00:00Z"},
+		{Time, "25:00"},
+		{Time, "8 o'clock"},
+	} {
+		_, err := Coerce(tc.valueType, tc.input)
+		assert.Error(t, err, "%s %v", tc.valueType, tc.input)
+	}
+}
+
+func TestFormatTemporal(t *testing.T) {
+	instant := time.Date(2024, 3, 9, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))
+
+	assert.Equal(t, "2024-03-09", Format(Date, time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2024-03-10T04:30:00Z", Format(DateTime, instant))
+	assert.Equal(t, "08:05:00", Format(Time, "08:05:00"))
+	assert.Nil(t, Format(DateTime, nil))
+}
+
+func TestParseRelativeTime(t *testing.T) {
+	now := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+
+	for value, expected := range map[string]time.Time{
+		"now":     now,
+		"now-7d":  time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC),
+		"now+2h":  time.Date(2024, 3, 9, 14, 0, 0, 0, time.UTC),
+		"now-30m": time.Date(2024, 3, 9, 11, 30, 0, 0, time.UTC),
+		"now-1w":  time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC),
+		"now-1M":  time.Date(2024, 2, 9, 12, 0, 0, 0, time.UTC),
+		"now+1y":  time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC),
+	} {
+		result, ok := ParseRelativeTime(value, now)
+		assert.True(t, ok, value)
+		assert.Equal(t, expected, result, value)
+	}
+
+	for _, value := range []string{"2024-03-09", "now-7", "now-d", "yesterday", "now*2d"} {
+		_, ok := ParseRelativeTime(value, now)
+		assert.False(t, ok, value)
+	}
+}
@@ -0,0 +1,62 @@
+package value_type
+
+import (
+	"database/sql/driver"
+	"strings"
+)
+
+// TextArray is the value of a text[] column. It is written as a Postgres array literal
+// so that the query builder does not expand it into a list of parameters.
+type TextArray []string
+
+func (a TextArray) Value() (driver.Value, error) {
+	elements := make([]string, len(a))
+	for i, element := range a {
+		elements[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(element) + `"`
+	}
+	return "{" + strings.Join(elements, ",") + "}", nil
+}
+
+// ParseTextArray reads the Postgres literal of a one-dimensional text array such as `{a,"b c"}`,
+// NULL elements are left out.
+func ParseTextArray(literal string) (TextArray, bool) {
+	if len(literal) < 2 || literal[0] != '{' || literal[len(literal)-1] != '}' {
+		return nil, false
+	}
+	body := literal[1 : len(literal)-1]
+	result := TextArray{}
+	if len(body) == 0 {
+		return result, true
+	}
+
+	var element strings.Builder
+	quoted, inQuotes, escaped := false, false, false
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		switch {
+		case escaped:
+			element.WriteByte(ch)
+			escaped = false
+		case ch == '\\':
+			escaped = true
+		case ch == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case ch == ',' && !inQuotes:
+			if quoted || element.String() != "NULL" {
+				result = append(result, element.String())
+			}
+			element.Reset()
+			quoted = false
+		default:
+			element.WriteByte(ch)
+		}
+	}
+	if inQuotes || escaped {
+		return nil, false
+	}
+	if quoted || element.String() != "NULL" {
+		result = append(result, element.String())
+	}
+	return result, true
+}
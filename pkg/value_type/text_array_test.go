@@ -0,0 +1,34 @@
+package value_type
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextArray_Value(t *testing.T) {
+	value, err := TextArray{"a", `b "c"`, `d\e`}.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a","b \"c\"","d\\e"}`, value)
+
+	value, _ = TextArray{}.Value()
+	assert.Equal(t, "{}", value)
+}
+
+func TestParseTextArray(t *testing.T) {
+	for literal, expected := range map[string]TextArray{
+		"{}":                   {},
+		"{a,b}":                {"a", "b"},
+		`{"b \"c\"","d\\e",f}`: {`b "c"`, `d\e`, "f"},
+		`{a,NULL,"NULL"}`:      {"a", "NULL"},
+	} {
+		ids, ok := ParseTextArray(literal)
+		assert.True(t, ok, literal)
+		assert.Equal(t, expected, ids, literal)
+	}
+
+	for _, literal := range []string{"", "a,b", `{"a}`} {
+		_, ok := ParseTextArray(literal)
+		assert.False(t, ok, literal)
+	}
+}
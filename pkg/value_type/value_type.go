@@ -13,6 +13,13 @@ const (
 	File       ValueType = "FILE"
 	Reference  ValueType = "REFERENCE"
 	References ValueType = "REFERENCES"
+	Date       ValueType = "DATE"
+	DateTime   ValueType = "DATETIME"
+	Time       ValueType = "TIME"
+	JSON       ValueType = "JSON"
+	Enum       ValueType = "ENUM"
+	RichText   ValueType = "RICH_TEXT"
+	Slug       ValueType = "SLUG"
 )
 
 var validValueTypes = map[ValueType]bool{
@@ -24,6 +31,13 @@ var validValueTypes = map[ValueType]bool{
 	File:       true,
 	Reference:  true,
 	References: true,
+	Date:       true,
+	DateTime:   true,
+	Time:       true,
+	JSON:       true,
+	Enum:       true,
+	RichText:   true,
+	Slug:       true,
 }
 
 func ParseValueType(value string) (ValueType, error) {
@@ -33,3 +47,13 @@ func ParseValueType(value string) (ValueType, error) {
 	}
 	return vt, nil
 }
+
+// Searchable reports whether properties of the value type can be part of the full-text search.
+func (vt ValueType) Searchable() bool {
+	return vt == String || vt == RichText
+}
+
+// Numeric reports whether properties of the value type can be summed and averaged.
+func (vt ValueType) Numeric() bool {
+	return vt == Integer || vt == Double || vt == Float
+}
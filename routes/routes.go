@@ -35,15 +35,33 @@ func InitRoutes(db *gorm.DB, redis *config.RedisClient) *gin.Engine {
 
 	helperService := helper_service.NewHelperService(db)
 	helperHandler := helper_handler.NewHelperHandler(nodeTypeService, helperService)
-	r.GET("helper/loadSchema", helperHandler.LoadSchema)
 	r.GET("helper/loadData", helperHandler.LoadData)
 	r.GET("helper/nodeType", helperHandler.FetchNodeType)
 	r.GET("helper/nodeType/delete", helperHandler.DeleteNodeType)
 
+	admin := r.Group("helper", middleware.RequireBearerToken(config.Env.AdminToken))
+	admin.GET("loadSchema", helperHandler.LoadSchema)
+	admin.GET("planSchema", helperHandler.PlanSchema)
+	admin.GET("nodeType/versions", helperHandler.FetchVersions)
+	admin.GET("nodeType/versions/diff", helperHandler.DiffVersions)
+	admin.POST("nodeType/rollback", helperHandler.Rollback)
+
+	schema := r.Group("schema", middleware.RequireBearerToken(config.Env.AdminToken))
+	schema.POST("nodeType", helperHandler.CreateNodeType)
+	schema.PUT("nodeType/:tid", helperHandler.ReplaceNodeType)
+	schema.PATCH("nodeType/:tid", helperHandler.PatchNodeType)
+	schema.DELETE("nodeType/:tid", helperHandler.DropNodeType)
+	schema.POST("nodeType/:tid/propertyType", helperHandler.AddPropertyType)
+	schema.PUT("nodeType/:tid/propertyType/:pid", helperHandler.ReplacePropertyType)
+	schema.PATCH("nodeType/:tid/propertyType/:pid", helperHandler.PatchPropertyType)
+	schema.DELETE("nodeType/:tid/propertyType/:pid", helperHandler.DeletePropertyType)
+
 	nodeTypeHandler := node_type_handler.NewNodeTypeHandler(nodeTypeService)
 	r.GET("info/:typeId", middleware.CheckNodeTypeExist(nodeTypeService), nodeTypeHandler.ReadNodeTypeInfo)
+	r.GET("aggregate/:typeId", middleware.CheckNodeTypeExist(nodeTypeService), nodeTypeHandler.AggregateApi)
 	r.GET("/:typeId", middleware.CheckNodeTypeExist(nodeTypeService), nodeTypeHandler.ListApi)
 	r.GET("/:typeId/:id", middleware.CheckNodeTypeExist(nodeTypeService), nodeTypeHandler.ReadApi)
+	r.GET("/:typeId/:id/related/:relatedTypeId", middleware.CheckNodeTypeExist(nodeTypeService), nodeTypeHandler.RelatedApi)
 	r.POST("/:typeId", middleware.CheckNodeTypeExist(nodeTypeService), nodeTypeHandler.CreateApi)
 	r.PATCH("/:typeId/:id", middleware.CheckNodeTypeExist(nodeTypeService), nodeTypeHandler.UpdateApi)
 	r.DELETE("/:typeId/:id", middleware.CheckNodeTypeExist(nodeTypeService), nodeTypeHandler.DeleteApi)